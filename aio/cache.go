@@ -10,10 +10,14 @@ package aio
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/sha1"
-	"encoding/binary"
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"log/slog"
-	"math"
+	"slices"
+	"time"
 
 	"github.com/kshard/embeddings"
 )
@@ -30,9 +34,53 @@ type KeyVal interface {
 	Putter
 }
 
+// Deleter is an optional interface implemented by KeyVal
+type Deleter interface{ Delete([]byte) error }
+
+// BatchGetter is an optional interface implemented by KeyVal.
+// It returns values of keys in the order of keys, nil if key is not found.
+type BatchGetter interface {
+	BatchGet([][]byte) ([][]byte, error)
+}
+
+// BatchPutter is an optional interface implemented by KeyVal.
+// It stores values of keys, the value vals[i] belongs to keys[i].
+type BatchPutter interface {
+	BatchPut(keys, vals [][]byte) error
+}
+
+// Cache is the caching layer for embeddings client. Embedding vectors are
+// stored in KeyVal under the hash of the text. The hash is namespaced by
+// the model identity, multiple models can share the same KeyVal safely.
+//
+// The model identity is obtained from the embedder if it implements
+// [embeddings.Modeler] interface, use Namespace method to supply it explicitly.
+// Keys of the cache without model identity are hash of the text only.
+// Use Secret method to make keys HMAC of the text, keys of known texts are
+// not revealed by the KeyVal.
+//
+// Values are self-describing, they carry version, dimension of the vector,
+// used tokens, creation time and checksum. Corrupted values and values of
+// unexpected dimension are cache misses. Use Purge method to delete them.
+// Values of legacy format (raw vectors) are silent misses, they are
+// overwritten.
+//
+// Concurrent misses of the same text are coalesced, only one request is
+// made to the embedder and all callers receive its result or error. If the
+// request is cancelled by context of its caller, other callers retry it.
+//
+// Use Stats method to obtain hits, misses, failures and tokens saved by
+// the cache. Cached embeddings are returned with zero used tokens.
 type Cache struct {
 	embeddings.Embedder
-	cache KeyVal
+	cache      KeyVal
+	namespace  string
+	secret     []byte
+	dimensions int
+	purge      bool
+	codec      Codec
+	flight     *flight
+	counters   counters
 }
 
 var _ embeddings.Embedder = (*Cache)(nil)
@@ -44,15 +92,66 @@ var _ embeddings.Embedder = (*Cache)(nil)
 //	cli, err := /* create embeddings client */
 //	db, err := pogreb.Open("embeddings.cache", nil)
 //	text := cache.NewCache(db, cli)
+//
+// Use [NewMemory] to cache embeddings in memory or [OpenDisk] to cache them
+// on local file system without external dependencies.
 func NewCache(cache KeyVal, embedder embeddings.Embedder) *Cache {
-	return &Cache{
+	c := &Cache{
 		Embedder: embedder,
 		cache:    cache,
+		flight:   newFlight(),
+	}
+
+	if m, ok := embedder.(embeddings.Modeler); ok {
+		c.Namespace(m.Model())
 	}
+
+	return c
+}
+
+// Namespace defines the model identity used by cache keys.
+func (c *Cache) Namespace(model embeddings.Model) {
+	c.namespace = model.String()
+	c.dimensions = model.Dimensions
 }
 
+// Secret defines the key of HMAC-SHA256 used by cache keys instead of SHA-1
+// hash of the text. Changing the secret invalidates the cache.
+func (c *Cache) Secret(secret []byte) {
+	c.secret = append([]byte(nil), secret...)
+}
+
+// Purge defines if corrupted values are deleted from KeyVal, the KeyVal
+// must implement Deleter interface. The default is false.
+func (c *Cache) Purge(enabled bool) {
+	c.purge = enabled
+}
+
+// Codec defines the storage format of vectors, quantized formats reduce
+// the size of cache at the cost of precision. Values of any format are
+// readable. The default is Float32, unknown codecs fall back to it.
+func (c *Cache) Codec(codec Codec) {
+	if codec.size(0) < 0 {
+		codec = Float32
+	}
+	c.codec = codec
+}
+
+// Stats returns statistics of the cache usage.
+func (c *Cache) Stats() CacheStats {
+	return c.counters.stats()
+}
+
+// HashKey returns the cache key of the text.
 func (c *Cache) HashKey(text string) []byte {
 	hash := sha1.New()
+	if len(c.secret) != 0 {
+		hash = hmac.New(sha256.New, c.secret)
+	}
+	if c.namespace != "" {
+		hash.Write([]byte(c.namespace))
+		hash.Write([]byte{0})
+	}
 	hash.Write([]byte(text))
 	return hash.Sum(nil)
 }
@@ -66,48 +165,349 @@ func (c *Cache) Embedding(ctx context.Context, text string) (embeddings.Embeddin
 		return embeddings.Embedding{}, err
 	}
 
-	if len(val) != 0 {
-		return embeddings.Embedding{
-			Text:   text,
-			Vector: decodeFVec(val),
-		}, nil
+	if reply, has := c.hit(hkey, text, val); has {
+		return reply, nil
+	}
+
+	return c.fetch(ctx, hkey, text)
+}
+
+// decodes cached value, corrupted value is a miss
+func (c *Cache) hit(hkey []byte, text string, val []byte) (embeddings.Embedding, bool) {
+	if len(val) == 0 {
+		return embeddings.Embedding{}, false
+	}
+
+	v, err := c.decode(val)
+	if errors.Is(err, errLegacy) {
+		return embeddings.Embedding{}, false
+	}
+	if err != nil {
+		c.corrupted(hkey, err)
+		return embeddings.Embedding{}, false
+	}
+
+	c.counters.hits.Add(1)
+	c.counters.tokensSaved.Add(int64(v.usedTokens))
+
+	return embeddings.Embedding{
+		Text:   text,
+		Vector: v.vector,
+	}, true
+}
+
+// calculates embedding vector, concurrent requests of the same text are
+// coalesced
+func (c *Cache) fetch(ctx context.Context, hkey []byte, text string) (embeddings.Embedding, error) {
+	reply, shared, err := c.flight.do(ctx, string(hkey),
+		func() (embeddings.Embedding, error) { return c.miss(ctx, hkey, text) },
+	)
+	if err != nil {
+		return embeddings.Embedding{}, err
+	}
+
+	if shared {
+		return c.shared(text, reply), nil
 	}
 
+	return reply, nil
+}
+
+// the reply of concurrent request is shared, only its caller is charged
+func (c *Cache) shared(text string, reply embeddings.Embedding) embeddings.Embedding {
+	c.counters.coalesced.Add(1)
+	c.counters.tokensSaved.Add(int64(reply.UsedTokens))
+	return embeddings.Embedding{
+		Text:   text,
+		Vector: slices.Clone(reply.Vector),
+	}
+}
+
+// calculates embedding vector and caches it
+func (c *Cache) miss(ctx context.Context, hkey []byte, text string) (embeddings.Embedding, error) {
+	c.counters.misses.Add(1)
+
 	reply, err := c.Embedder.Embedding(ctx, text)
 	if err != nil {
 		return embeddings.Embedding{}, err
 	}
 
-	err = c.cache.Put(hkey, encodeFVec(reply.Vector))
+	val, err := c.encode(reply)
+	if err == nil {
+		err = c.cache.Put(hkey, val)
+	}
 	if err != nil {
+		c.counters.putFailures.Add(1)
 		slog.Warn("failed to cache vector", "error", err)
 	}
 
 	return reply, nil
 }
 
-func encodeFVec(v []float32) []byte {
-	b := make([]byte, len(v)*4)
+// BatchCache is the caching layer for embeddings client with batch api,
+// it implements [embeddings.BatchEmbedder]. See [Cache] for details.
+//
+//	text := aio.NewBatchCache(db, cli)
+//	seq, err := text.Embeddings(ctx, []string{"hello", "world"})
+type BatchCache struct {
+	*Cache
+	batch embeddings.BatchEmbedder
+}
 
-	p := 0
-	for i := 0; i < len(v); i++ {
-		u := math.Float32bits(v[i])
-		binary.LittleEndian.PutUint32(b[p:p+4], u)
+var _ embeddings.BatchEmbedder = (*BatchCache)(nil)
 
-		p += 4
+// Creates caching layer for embeddings client with batch api.
+func NewBatchCache(cache KeyVal, embedder embeddings.BatchEmbedder) *BatchCache {
+	return &BatchCache{
+		Cache: NewCache(cache, embedder),
+		batch: embedder,
 	}
+}
 
-	return b
+// Calculates embedding vectors of texts, embeddings are returned in
+// the order of texts. Cached vectors are looked up within single request
+// if KeyVal implements BatchGetter. Only misses are forwarded to embedder
+// within single request, misses in flight are coalesced.
+func (c *BatchCache) Embeddings(ctx context.Context, text []string) ([]embeddings.Embedding, error) {
+	keys := make([][]byte, len(text))
+	for i, x := range text {
+		keys[i] = c.HashKey(x)
+	}
+
+	vals, err := c.getAll(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// misses are grouped by key, the first occurrence is requested
+	seq := make([]embeddings.Embedding, len(text))
+	misses := make(map[string][]int)
+	order := make([]int, 0)
+	for i, x := range text {
+		if reply, has := c.hit(keys[i], x, vals[i]); has {
+			seq[i] = reply
+			continue
+		}
+
+		hkey := string(keys[i])
+		if _, has := misses[hkey]; !has {
+			order = append(order, i)
+		}
+		misses[hkey] = append(misses[hkey], i)
+	}
+
+	if len(order) == 0 {
+		return seq, nil
+	}
+
+	replies, err := c.fetchAll(ctx, keys, text, order)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, at := range order {
+		reply := replies[k]
+		for j, i := range misses[string(keys[at])] {
+			if j == 0 {
+				seq[i] = reply
+				continue
+			}
+
+			c.counters.coalesced.Add(1)
+			c.counters.tokensSaved.Add(int64(reply.UsedTokens))
+			seq[i] = embeddings.Embedding{Text: text[i], Vector: slices.Clone(reply.Vector)}
+		}
+	}
+
+	return seq, nil
 }
 
-func decodeFVec(b []byte) []float32 {
-	v := make([]float32, len(b)/4)
+// calculates embedding vectors of texts at positions and caches them,
+// texts in flight by concurrent requests are awaited.
+func (c *BatchCache) fetchAll(ctx context.Context, keys [][]byte, text []string, at []int) ([]embeddings.Embedding, error) {
+	calls := make([]*call, len(at))
+	lead := make([]int, 0, len(at))
+	for k, i := range at {
+		x, leader := c.flight.begin(string(keys[i]))
+		calls[k] = x
+		if leader {
+			lead = append(lead, k)
+		}
+	}
+
+	seq := make([]embeddings.Embedding, len(at))
+	if len(lead) > 0 {
+		hkeys := make([][]byte, len(lead))
+		txt := make([]string, len(lead))
+		led := make([]*call, len(lead))
+		for j, k := range lead {
+			hkeys[j], txt[j], led[j] = keys[at[k]], text[at[k]], calls[k]
+			calls[k] = nil
+		}
+
+		replies, err := c.missAll(ctx, hkeys, txt, led)
+		if err != nil {
+			return nil, err
+		}
 
-	p := 0
-	for i := 0; i < len(b); i += 4 {
-		v[p] = math.Float32frombits(binary.LittleEndian.Uint32(b[i : i+4]))
-		p++
+		for j, k := range lead {
+			seq[k] = replies[j]
+		}
 	}
 
-	return v
+	for k, i := range at {
+		if calls[k] == nil {
+			continue
+		}
+
+		reply, done, err := c.flight.wait(ctx, calls[k])
+		if !done {
+			reply, err = c.fetch(ctx, keys[i], text[i])
+			if err != nil {
+				return nil, err
+			}
+			seq[k] = reply
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		seq[k] = c.shared(text[i], reply)
+	}
+
+	return seq, nil
+}
+
+// calculates embedding vectors of texts within single request and caches
+// them, the reply is shared with waiters of calls.
+func (c *BatchCache) missAll(ctx context.Context, keys [][]byte, text []string, calls []*call) (seq []embeddings.Embedding, err error) {
+	defer func() {
+		r := recover()
+		if r != nil {
+			err = fmt.Errorf("embedding has panicked: %v", r)
+		}
+
+		for k, x := range calls {
+			var reply embeddings.Embedding
+			if err == nil {
+				reply = seq[k]
+			}
+			c.flight.end(string(keys[k]), x, reply, err, err != nil && ctx.Err() != nil)
+		}
+
+		if r != nil {
+			panic(r)
+		}
+	}()
+
+	c.counters.misses.Add(int64(len(text)))
+	seq, err = c.batch.Embeddings(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if len(seq) != len(text) {
+		return nil, fmt.Errorf("embedding has failed: expected %d vectors, got %d", len(text), len(seq))
+	}
+
+	hkeys := make([][]byte, 0, len(seq))
+	vals := make([][]byte, 0, len(seq))
+	for k := range seq {
+		val, err := c.encode(seq[k])
+		if err != nil {
+			c.counters.putFailures.Add(1)
+			slog.Warn("failed to cache vector", "error", err)
+			continue
+		}
+		hkeys = append(hkeys, keys[k])
+		vals = append(vals, val)
+	}
+	if len(hkeys) > 0 {
+		c.putAll(hkeys, vals)
+	}
+
+	return seq, nil
+}
+
+// looks up values of keys, using batch api if KeyVal supports it
+func (c *Cache) getAll(keys [][]byte) ([][]byte, error) {
+	if kv, ok := c.cache.(BatchGetter); ok {
+		vals, err := kv.BatchGet(keys)
+		if err != nil {
+			return nil, err
+		}
+		if len(vals) != len(keys) {
+			return nil, fmt.Errorf("batch get has failed: expected %d values, got %d", len(keys), len(vals))
+		}
+		return vals, nil
+	}
+
+	vals := make([][]byte, len(keys))
+	for i, key := range keys {
+		val, err := c.cache.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+
+	return vals, nil
+}
+
+// stores values of keys, using batch api if KeyVal supports it
+func (c *Cache) putAll(keys, vals [][]byte) {
+	if kv, ok := c.cache.(BatchPutter); ok {
+		if err := kv.BatchPut(keys, vals); err != nil {
+			c.counters.putFailures.Add(int64(len(keys)))
+			slog.Warn("failed to cache vectors", "error", err)
+		}
+		return
+	}
+
+	for i, key := range keys {
+		if err := c.cache.Put(key, vals[i]); err != nil {
+			c.counters.putFailures.Add(1)
+			slog.Warn("failed to cache vector", "error", err)
+		}
+	}
+}
+
+func (c *Cache) encode(reply embeddings.Embedding) ([]byte, error) {
+	return encodeValue(
+		value{
+			vector:     reply.Vector,
+			usedTokens: reply.UsedTokens,
+			created:    time.Now(),
+		},
+		c.codec,
+	)
+}
+
+func (c *Cache) decode(val []byte) (value, error) {
+	v, err := decodeValue(val)
+	if err != nil {
+		return value{}, err
+	}
+
+	if c.dimensions != 0 && len(v.vector) != c.dimensions {
+		return value{}, fmt.Errorf("%w: expected %d dimensions, got %d", errCorrupted, c.dimensions, len(v.vector))
+	}
+
+	return v, nil
+}
+
+// corrupted value is a cache miss, it is deleted if purge is enabled
+func (c *Cache) corrupted(hkey []byte, err error) {
+	c.counters.decodeFailures.Add(1)
+	slog.Warn("corrupted cache vector", "error", err)
+
+	if !c.purge {
+		return
+	}
+
+	if kv, ok := c.cache.(Deleter); ok {
+		if err := kv.Delete(hkey); err != nil {
+			slog.Warn("failed to delete cache vector", "error", err)
+		}
+	}
 }
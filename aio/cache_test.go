@@ -11,8 +11,15 @@ package aio_test
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"sync"
 	"testing"
 
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings"
 	"github.com/kshard/embeddings/aio"
 )
 
@@ -35,6 +42,388 @@ func TestCache(t *testing.T) {
 	}
 }
 
+func TestCacheNamespace(t *testing.T) {
+	text := "hello world"
+	sum := sha1.Sum([]byte(text))
+
+	t.Run("Default", func(t *testing.T) {
+		c := aio.NewCache(keyval{}, mockVector())
+
+		it.Then(t).Should(
+			it.Equal(string(c.HashKey(text)), string(sum[:])),
+		)
+	})
+
+	t.Run("Explicit", func(t *testing.T) {
+		a := aio.NewCache(keyval{}, mockVector())
+		a.Namespace(embeddings.Model{Provider: "bedrock", ID: "titan", Dimensions: 256})
+
+		b := aio.NewCache(keyval{}, mockVector())
+		b.Namespace(embeddings.Model{Provider: "bedrock", ID: "titan", Dimensions: 512})
+
+		it.Then(t).ShouldNot(
+			it.Equal(string(a.HashKey(text)), string(sum[:])),
+			it.Equal(string(a.HashKey(text)), string(b.HashKey(text))),
+		)
+	})
+
+	t.Run("Secret", func(t *testing.T) {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(text))
+
+		c := aio.NewCache(keyval{}, mockVector())
+		c.Secret([]byte("secret"))
+
+		it.Then(t).Should(
+			it.Equal(string(c.HashKey(text)), string(mac.Sum(nil))),
+		)
+	})
+
+	t.Run("SecretNamespace", func(t *testing.T) {
+		model := embeddings.Model{Provider: "openai", ID: "text-embedding-3-small"}
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(model.String()))
+		mac.Write([]byte{0})
+		mac.Write([]byte(text))
+
+		a := aio.NewCache(keyval{}, mockVector())
+		a.Secret([]byte("secret"))
+		a.Namespace(model)
+
+		b := aio.NewCache(keyval{}, mockVector())
+		b.Secret([]byte("secret"))
+
+		c := aio.NewCache(keyval{}, mockVector())
+		c.Namespace(model)
+
+		it.Then(t).Should(
+			it.Equal(string(a.HashKey(text)), string(mac.Sum(nil))),
+		).ShouldNot(
+			it.Equal(string(a.HashKey(text)), string(b.HashKey(text))),
+			it.Equal(string(a.HashKey(text)), string(c.HashKey(text))),
+		)
+	})
+
+	t.Run("Modeler", func(t *testing.T) {
+		kv := keyval{}
+		a := aio.NewCache(kv, modeler{mockVector(), embeddings.Model{Provider: "openai", ID: "text-embedding-3-large"}})
+		b := aio.NewCache(kv, modeler{mockVector(), embeddings.Model{Provider: "openai", ID: "text-embedding-3-small"}})
+
+		a.Embedding(context.Background(), text)
+		b.Embedding(context.Background(), text)
+
+		it.Then(t).Should(
+			it.Equal(len(kv), 2),
+		)
+	})
+}
+
+func TestCacheValue(t *testing.T) {
+	text := "hello world"
+
+	t.Run("Hit", func(t *testing.T) {
+		api := &counter{mock: mockVector()}
+		c := aio.NewCache(keyval{}, api)
+
+		a, erra := c.Embedding(context.Background(), text)
+		b, errb := c.Embedding(context.Background(), text)
+
+		it.Then(t).Should(
+			it.Nil(erra),
+			it.Nil(errb),
+			it.Equal(api.calls, 1),
+			it.Seq(b.Vector).Equal(a.Vector...),
+		)
+	})
+
+	t.Run("Corrupted", func(t *testing.T) {
+		kv := keyval{}
+		api := &counter{mock: mockVector()}
+		c := aio.NewCache(kv, api)
+		c.Embedding(context.Background(), text)
+
+		kv[string(c.HashKey(text))][20] ^= 0xff
+
+		v, err := c.Embedding(context.Background(), text)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(api.calls, 2),
+			it.Seq(v.Vector).Equal(mockVector().reply.Vector...),
+		)
+
+		c.Embedding(context.Background(), text)
+		it.Then(t).Should(
+			it.Equal(api.calls, 2),
+		)
+	})
+
+	t.Run("Legacy", func(t *testing.T) {
+		kv := keyval{}
+		api := &counter{mock: mockVector()}
+		c := aio.NewCache(kv, api)
+		kv[string(c.HashKey(text))] = []byte{0, 0, 128, 63, 0, 0, 0, 64}
+
+		c.Embedding(context.Background(), text)
+		c.Embedding(context.Background(), text)
+		it.Then(t).Should(
+			it.Equal(api.calls, 1),
+			it.Equal(c.Stats().DecodeFailures, 0),
+		)
+	})
+
+	t.Run("Dimensions", func(t *testing.T) {
+		kv := keyval{}
+		api := &counter{mock: mockVector()}
+		model := embeddings.Model{Provider: "bedrock", ID: "titan", Dimensions: 256}
+
+		a := aio.NewCache(kv, api)
+		a.Namespace(model)
+		a.Embedding(context.Background(), text)
+
+		b := aio.NewCache(kv, api)
+		b.Namespace(model)
+		b.Embedding(context.Background(), text)
+
+		it.Then(t).Should(
+			it.Equal(api.calls, 2),
+		)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		kv := keyval{}
+		api := &counter{mock: mockVector()}
+		c := aio.NewCache(kv, api)
+		c.Purge(true)
+		c.Embedding(context.Background(), text)
+
+		kv[string(c.HashKey(text))][20] ^= 0xff
+		api.err = fmt.Errorf("failed")
+
+		_, err := c.Embedding(context.Background(), text)
+		it.Then(t).Should(
+			it.Fail(func() error { return err }),
+			it.Equal(len(kv), 0),
+		)
+	})
+}
+
+func TestCacheSingleflight(t *testing.T) {
+	text := "hello world"
+
+	embed := func(c *aio.Cache, n int) []error {
+		errs := make([]error, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				v, err := c.Embedding(context.Background(), text)
+				if err == nil && len(v.Vector) != len(mockVector().reply.Vector) {
+					err = fmt.Errorf("invalid vector")
+				}
+				errs[i] = err
+			}()
+		}
+		wg.Wait()
+		return errs
+	}
+
+	t.Run("Coalesced", func(t *testing.T) {
+		api := &blocking{mock: mockVector(), release: make(chan struct{})}
+		c := aio.NewCache(aio.NewMemory(10), api)
+
+		var waiting sync.WaitGroup
+		waiting.Add(7)
+		c.OnWait(waiting.Done)
+		go func() {
+			waiting.Wait()
+			close(api.release)
+		}()
+		errs := embed(c, 8)
+
+		it.Then(t).Should(
+			it.Equal(api.calls.Load(), 1),
+			it.Seq(errs).Equal(nil, nil, nil, nil, nil, nil, nil, nil),
+		)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		api := &blocking{mock: mockVector(), release: make(chan struct{}), err: fmt.Errorf("failed")}
+		c := aio.NewCache(aio.NewMemory(10), api)
+
+		var waiting sync.WaitGroup
+		waiting.Add(7)
+		c.OnWait(waiting.Done)
+		go func() {
+			waiting.Wait()
+			close(api.release)
+		}()
+		errs := embed(c, 8)
+
+		it.Then(t).Should(
+			it.Equal(api.calls.Load(), 1),
+		)
+		for _, err := range errs {
+			it.Then(t).Should(
+				it.Equal(err.Error(), "failed"),
+			)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		api := &blocking{mock: mockVector(), called: make(chan struct{}, 2), release: make(chan struct{})}
+		c := aio.NewCache(aio.NewMemory(10), api)
+
+		var waiting sync.WaitGroup
+		waiting.Add(1)
+		c.OnWait(waiting.Done)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		leader := make(chan error)
+		go func() {
+			_, err := c.Embedding(ctx, text)
+			leader <- err
+		}()
+		<-api.called
+
+		waiter := make(chan error)
+		go func() {
+			_, err := c.Embedding(context.Background(), text)
+			waiter <- err
+		}()
+		waiting.Wait()
+		cancel()
+
+		<-api.called
+		close(api.release)
+
+		it.Then(t).Should(
+			it.Equal(<-leader, context.Canceled),
+			it.Nil(<-waiter),
+			it.Equal(api.calls.Load(), 2),
+		)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		api := &panicking{blocking{mock: mockVector(), called: make(chan struct{}, 1), release: make(chan struct{})}}
+		c := aio.NewCache(aio.NewMemory(10), api)
+
+		var waiting sync.WaitGroup
+		waiting.Add(1)
+		c.OnWait(waiting.Done)
+
+		leader := make(chan any)
+		go func() {
+			defer func() { leader <- recover() }()
+			c.Embedding(context.Background(), text)
+		}()
+		<-api.called
+
+		waiter := make(chan error)
+		go func() {
+			_, err := c.Embedding(context.Background(), text)
+			waiter <- err
+		}()
+		waiting.Wait()
+		close(api.release)
+
+		it.Then(t).Should(
+			it.Equal(<-leader, any("failed")),
+			it.Equal((<-waiter).Error(), "embedding has panicked: failed"),
+		)
+	})
+}
+
+func TestCacheBatch(t *testing.T) {
+	text := []string{"a", "bb", "a", "ccc"}
+	vectors := func(seq []embeddings.Embedding) []float32 {
+		v := make([]float32, len(seq))
+		for i, x := range seq {
+			v[i] = x.Vector[0]
+		}
+		return v
+	}
+
+	t.Run("BatchEmbedder", func(t *testing.T) {
+		kv := &batchkv{Memory: aio.NewMemory(10)}
+		api := &batcher{}
+		c := aio.NewBatchCache(kv, api)
+		c.Embeddings(context.Background(), []string{"bb"})
+
+		seq, err := c.Embeddings(context.Background(), text)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(vectors(seq)).Equal(1, 2, 1, 3),
+			it.Equal(api.calls, 2),
+			it.Seq(api.texts).Equal("bb", "a", "ccc"),
+			it.Equal(kv.gets, 2),
+			it.Equal(kv.puts, 2),
+			it.Equal(c.Stats(), aio.CacheStats{Hits: 1, Misses: 3, Coalesced: 1, TokensSaved: 3}),
+		)
+	})
+
+	t.Run("Coalesced", func(t *testing.T) {
+		api := &batcher{called: make(chan struct{}, 2), release: make(chan struct{})}
+		c := aio.NewBatchCache(aio.NewMemory(10), api)
+
+		a := make(chan []embeddings.Embedding)
+		go func() {
+			seq, _ := c.Embeddings(context.Background(), []string{"a"})
+			a <- seq
+		}()
+		<-api.called
+
+		b := make(chan []embeddings.Embedding)
+		go func() {
+			seq, _ := c.Embeddings(context.Background(), []string{"a", "bb"})
+			b <- seq
+		}()
+		<-api.called
+		close(api.release)
+
+		it.Then(t).Should(
+			it.Seq(vectors(<-a)).Equal(1),
+			it.Seq(vectors(<-b)).Equal(1, 2),
+			it.Seq(api.texts).Equal("a", "bb"),
+			it.Equal(c.Stats(), aio.CacheStats{Misses: 2, Coalesced: 1, TokensSaved: 1}),
+		)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		api := &batcher{err: fmt.Errorf("failed")}
+		c := aio.NewBatchCache(keyval{}, api)
+
+		_, err := c.Embeddings(context.Background(), text)
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+}
+
+// mock key-value counting batch requests
+type batchkv struct {
+	*aio.Memory
+	gets, puts int
+}
+
+func (kv *batchkv) BatchGet(keys [][]byte) ([][]byte, error) {
+	kv.gets++
+	return kv.Memory.BatchGet(keys)
+}
+
+func (kv *batchkv) BatchPut(keys, vals [][]byte) error {
+	kv.puts++
+	return kv.Memory.BatchPut(keys, vals)
+}
+
+// mock embedding client with model identity
+type modeler struct {
+	mock
+	model embeddings.Model
+}
+
+func (m modeler) Model() embeddings.Model { return m.model }
+
 // mock key-value
 type keyval map[string][]byte
 
@@ -51,3 +440,8 @@ func (kv keyval) Put(key []byte, val []byte) error {
 	kv[string(key)] = val
 	return nil
 }
+
+func (kv keyval) Delete(key []byte) error {
+	delete(kv, string(key))
+	return nil
+}
@@ -0,0 +1,251 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Codec is the storage format of vector elements in the cache. The codec is
+// recorded in the value, the cache reads values of any codec.
+type Codec uint8
+
+const (
+	// Float32 stores elements as is, 4 bytes per element.
+	Float32 Codec = iota
+
+	// Float16 stores elements as IEEE 754 half precision, 2 bytes per element.
+	Float16
+
+	// BFloat16 stores elements as brain floating point, 2 bytes per element.
+	// It keeps the range of float32 at the cost of precision.
+	BFloat16
+
+	// Int8 stores elements as signed bytes scaled by the maximum absolute
+	// value of the vector, 1 byte per element and 4 bytes of the scale.
+	// Vectors with non-finite elements are not cached.
+	Int8
+
+	// Binary stores signs of elements, 1 bit per element and 4 bytes of the
+	// scale (mean absolute value). Elements are decoded as ±scale.
+	// Vectors with non-finite elements are not cached.
+	Binary
+)
+
+func (c Codec) String() string {
+	switch c {
+	case Float32:
+		return "float32"
+	case Float16:
+		return "float16"
+	case BFloat16:
+		return "bfloat16"
+	case Int8:
+		return "int8"
+	case Binary:
+		return "binary"
+	default:
+		return "codec(" + strconv.Itoa(int(c)) + ")"
+	}
+}
+
+// size of encoded vector in bytes, -1 if codec is unknown
+func (c Codec) size(dim int) int {
+	switch c {
+	case Float32:
+		return dim * 4
+	case Float16, BFloat16:
+		return dim * 2
+	case Int8:
+		return 4 + dim
+	case Binary:
+		return 4 + (dim+7)/8
+	default:
+		return -1
+	}
+}
+
+// encode vector into the buffer of the codec size. Scaled codecs reject
+// non-finite elements, they would corrupt the scale of the vector.
+func (c Codec) encode(b []byte, v []float32) error {
+	if c == Int8 || c == Binary {
+		for i, x := range v {
+			if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+				return fmt.Errorf("%s codec has failed: non-finite element %d", c, i)
+			}
+		}
+	}
+
+	switch c {
+	case Float32:
+		for i, x := range v {
+			binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(x))
+		}
+	case Float16:
+		for i, x := range v {
+			binary.LittleEndian.PutUint16(b[i*2:], toFloat16(x))
+		}
+	case BFloat16:
+		for i, x := range v {
+			binary.LittleEndian.PutUint16(b[i*2:], toBFloat16(x))
+		}
+	case Int8:
+		amax := float32(0.0)
+		for _, x := range v {
+			amax = max(amax, abs(x))
+		}
+
+		scale := amax / 127
+		binary.LittleEndian.PutUint32(b, math.Float32bits(scale))
+		if scale == 0 {
+			return nil
+		}
+		for i, x := range v {
+			q := math.Round(float64(x / scale))
+			b[4+i] = byte(int8(min(max(q, -127), 127)))
+		}
+	case Binary:
+		sum := float32(0.0)
+		for _, x := range v {
+			sum += abs(x)
+		}
+
+		scale := float32(0.0)
+		if len(v) > 0 {
+			scale = sum / float32(len(v))
+		}
+		binary.LittleEndian.PutUint32(b, math.Float32bits(scale))
+		for i, x := range v {
+			if x >= 0 {
+				b[4+i/8] |= 1 << (i % 8)
+			}
+		}
+	}
+	return nil
+}
+
+// decode vector from the buffer of the codec size
+func (c Codec) decode(b []byte, v []float32) {
+	switch c {
+	case Float32:
+		for i := range v {
+			v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+		}
+	case Float16:
+		for i := range v {
+			v[i] = fromFloat16(binary.LittleEndian.Uint16(b[i*2:]))
+		}
+	case BFloat16:
+		for i := range v {
+			v[i] = math.Float32frombits(uint32(binary.LittleEndian.Uint16(b[i*2:])) << 16)
+		}
+	case Int8:
+		scale := math.Float32frombits(binary.LittleEndian.Uint32(b))
+		for i := range v {
+			v[i] = float32(int8(b[4+i])) * scale
+		}
+	case Binary:
+		scale := math.Float32frombits(binary.LittleEndian.Uint32(b))
+		for i := range v {
+			if b[4+i/8]&(1<<(i%8)) != 0 {
+				v[i] = scale
+			} else {
+				v[i] = -scale
+			}
+		}
+	}
+}
+
+func abs(x float32) float32 {
+	return math.Float32frombits(math.Float32bits(x) &^ (1 << 31))
+}
+
+// float32 to bfloat16, rounding to nearest even
+func toBFloat16(x float32) uint16 {
+	u := math.Float32bits(x)
+	if u&0x7fffffff > 0x7f800000 {
+		// NaN, keep it quiet
+		return uint16(u>>16) | 0x0040
+	}
+
+	u += 0x7fff + (u>>16)&1
+	return uint16(u >> 16)
+}
+
+// float32 to IEEE 754 half precision, rounding to nearest even
+func toFloat16(x float32) uint16 {
+	u := math.Float32bits(x)
+	sign := uint16(u>>16) & 0x8000
+	exp := int((u >> 23) & 0xff)
+	man := u & 0x7fffff
+
+	switch {
+	case exp == 0xff:
+		// Inf or NaN
+		if man != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+
+	case exp-127+15 >= 0x1f:
+		// overflow to Inf
+		return sign | 0x7c00
+
+	case exp-127+15 <= 0:
+		// subnormal or zero
+		shift := 14 - (exp - 127 + 15)
+		if shift > 24 {
+			return sign
+		}
+		man |= 0x800000
+		half := uint32(1) << (shift - 1)
+		rest := man & (1<<shift - 1)
+		h := man >> shift
+		if rest > half || (rest == half && h&1 == 1) {
+			h++
+		}
+		return sign | uint16(h)
+
+	default:
+		h := uint32(exp-127+15)<<10 | man>>13
+		rest := man & 0x1fff
+		if rest > 0x1000 || (rest == 0x1000 && h&1 == 1) {
+			// carry may overflow into exponent, it is correct rounding
+			h++
+		}
+		return sign | uint16(h)
+	}
+}
+
+// IEEE 754 half precision to float32
+func fromFloat16(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	man := uint32(h & 0x3ff)
+
+	switch {
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | man<<13)
+	case exp == 0 && man == 0:
+		return math.Float32frombits(sign)
+	case exp == 0:
+		// subnormal, normalize it
+		e := uint32(127 - 15 + 1)
+		for man&0x400 == 0 {
+			man <<= 1
+			e--
+		}
+		return math.Float32frombits(sign | e<<23 | (man&0x3ff)<<13)
+	default:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | man<<13)
+	}
+}
@@ -0,0 +1,105 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio"
+)
+
+func TestCodec(t *testing.T) {
+	vector := []float32{0.1, -2.5, 1.0, -0.0001, 0.75, 3.0, -1.25, 0.0, 0.5}
+
+	for codec, tt := range map[aio.Codec]struct {
+		size int
+		eps  float64
+	}{
+		aio.Float32:  {size: 18 + 4 + 36, eps: 0},
+		aio.Float16:  {size: 18 + 4 + 18, eps: 2e-3},
+		aio.BFloat16: {size: 18 + 4 + 18, eps: 1e-2},
+		aio.Int8:     {size: 18 + 4 + 4 + 9, eps: 3.0 / 127 / 2},
+	} {
+		t.Run(codec.String(), func(t *testing.T) {
+			kv := keyval{}
+			c := aio.NewCache(kv, fixed(vector))
+			c.Codec(codec)
+			c.Embedding(context.Background(), "text")
+
+			v, err := aio.NewCache(kv, fixed(nil)).Embedding(context.Background(), "text")
+			it.Then(t).Should(
+				it.Nil(err),
+				it.Equal(len(kv[string(c.HashKey("text"))]), tt.size),
+				it.Equal(len(v.Vector), len(vector)),
+			)
+
+			for i, x := range v.Vector {
+				it.Then(t).ShouldNot(
+					it.Less(tt.eps, math.Abs(float64(x-vector[i]))),
+				)
+			}
+		})
+	}
+
+	t.Run("binary", func(t *testing.T) {
+		kv := keyval{}
+		c := aio.NewCache(kv, fixed(vector))
+		c.Codec(aio.Binary)
+		c.Embedding(context.Background(), "text")
+
+		v, err := aio.NewCache(kv, fixed(nil)).Embedding(context.Background(), "text")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(kv[string(c.HashKey("text"))]), 18+4+4+2),
+			it.Equal(len(v.Vector), len(vector)),
+		)
+
+		for i, x := range v.Vector {
+			it.Then(t).Should(
+				it.Equal(x > 0, vector[i] >= 0),
+			)
+		}
+	})
+
+	t.Run("float16/range", func(t *testing.T) {
+		special := []float32{65504, 1e6, -1e6, 6e-8, 1e-9, float32(math.Inf(-1))}
+
+		kv := keyval{}
+		c := aio.NewCache(kv, fixed(special))
+		c.Codec(aio.Float16)
+		c.Embedding(context.Background(), "text")
+
+		v, _ := aio.NewCache(kv, fixed(nil)).Embedding(context.Background(), "text")
+		it.Then(t).Should(
+			it.Equal(v.Vector[0], 65504),
+			it.True(math.IsInf(float64(v.Vector[1]), 1)),
+			it.True(math.IsInf(float64(v.Vector[2]), -1)),
+			it.Equal(v.Vector[3], float32(math.Ldexp(1, -24))),
+			it.Equal(v.Vector[4], 0),
+			it.True(math.IsInf(float64(v.Vector[5]), -1)),
+		)
+	})
+
+	t.Run("int8/nan", func(t *testing.T) {
+		kv := keyval{}
+		c := aio.NewCache(kv, fixed{1, float32(math.NaN()), -1})
+		c.Codec(aio.Int8)
+		v, err := c.Embedding(context.Background(), "text")
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(v.Vector), 3),
+			it.Equal(len(kv), 0),
+			it.Equal(c.Stats().PutFailures, 1),
+		)
+	})
+}
@@ -0,0 +1,716 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Disk is persistent KeyVal on local file system, it is safe for concurrent
+// use. It is append-only log-structured storage: records are appended to
+// the active segment file, the segment is sealed when it exceeds the size
+// limit. The index of records is kept in memory, it is rebuilt on open.
+// Each record carries checksum, torn writes at the tail of segments are
+// discarded on open. Sealed segments and the directory are synced, use Sync
+// method to sync each write.
+//
+// Overwritten and deleted records are garbage, the log is compacted when
+// garbage exceeds half of the log size at the segment rotation. The log is
+// compacted in background, Close waits for it. Use Compact method to compact
+// the log explicitly. The compaction copies live records without blocking
+// reads and writes.
+//
+// Keys of embeddings cache are fixed-size hashes and values are vectors,
+// the lookup costs single read of the value.
+//
+//	db, err := aio.OpenDisk("embeddings.cache")
+//	defer db.Close()
+//	text := aio.NewCache(db, cli)
+type Disk struct {
+	mu              sync.RWMutex
+	confSegmentSize int64
+	confSync        bool
+	dir             string
+	segments        map[int]*os.File
+	active          int
+	offset          int64
+	index           map[string]location
+	size            int64
+	garbage         int64
+	rotated         bool
+	compacting      bool
+	background      sync.WaitGroup
+}
+
+var (
+	_ KeyVal      = (*Disk)(nil)
+	_ Deleter     = (*Disk)(nil)
+	_ BatchGetter = (*Disk)(nil)
+	_ BatchPutter = (*Disk)(nil)
+)
+
+// location of the record's value
+type location struct {
+	segment int
+	offset  int64
+	length  int
+}
+
+// The record of the log (little endian):
+//
+//	checksum uint32 (CRC-32C of following bytes)
+//	flags    uint8
+//	klen     uint32
+//	vlen     uint32
+//	key      [klen]byte
+//	val      [vlen]byte
+const (
+	recordHeaderSize = 4 + 1 + 4 + 4
+	recordTombstone  = 1
+	segmentExt       = ".seg"
+)
+
+var errTornRecord = errors.New("torn record")
+
+// Opens persistent KeyVal at the directory, the directory is created if
+// it does not exist.
+func OpenDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	d := &Disk{
+		confSegmentSize: 64 << 20,
+		dir:             dir,
+		segments:        make(map[int]*os.File),
+		index:           make(map[string]location),
+	}
+
+	if err := d.open(); err != nil {
+		d.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// SegmentSize defines the size limit of segment in bytes.
+// The default value is 64 MiB.
+func (d *Disk) SegmentSize(n int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.confSegmentSize = max(n, 1)
+}
+
+// Sync defines if each write is synced to the storage, it makes writes
+// durable against power loss at the cost of latency. Without sync, writes
+// survive the process crash only. The default is false.
+func (d *Disk) Sync(enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.confSync = enabled
+}
+
+// Get returns value of the key, it returns nil if key is not found.
+func (d *Disk) Get(key []byte) ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.get(key)
+}
+
+// BatchGet returns values of keys, nil if key is not found.
+func (d *Disk) BatchGet(keys [][]byte) ([][]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	vals := make([][]byte, len(keys))
+	for i, key := range keys {
+		val, err := d.get(key)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+
+	return vals, nil
+}
+
+func (d *Disk) get(key []byte) ([]byte, error) {
+	loc, has := d.index[string(key)]
+	if !has {
+		return nil, nil
+	}
+
+	fd, has := d.segments[loc.segment]
+	if !has {
+		return nil, os.ErrClosed
+	}
+
+	val := make([]byte, loc.length)
+	if _, err := fd.ReadAt(val, loc.offset); err != nil {
+		return nil, err
+	}
+
+	return val, nil
+}
+
+// Put stores value of the key.
+func (d *Disk) Put(key []byte, val []byte) error {
+	return d.update(func() error { return d.append(0, key, val) })
+}
+
+// BatchPut stores values of keys, the segment is synced once per batch.
+func (d *Disk) BatchPut(keys, vals [][]byte) error {
+	if len(keys) != len(vals) {
+		return fmt.Errorf("batch put has failed: %d keys, %d values", len(keys), len(vals))
+	}
+
+	return d.update(func() error {
+		sync := d.confSync
+		d.confSync = false
+		defer func() { d.confSync = sync }()
+
+		for i, key := range keys {
+			if err := d.append(0, key, vals[i]); err != nil {
+				return err
+			}
+		}
+
+		if sync {
+			return d.segments[d.active].Sync()
+		}
+
+		return nil
+	})
+}
+
+// Delete removes the key.
+func (d *Disk) Delete(key []byte) error {
+	return d.update(func() error {
+		if _, has := d.index[string(key)]; !has {
+			return nil
+		}
+
+		return d.append(recordTombstone, key, nil)
+	})
+}
+
+// applies changes under the write lock, the log is compacted in background
+// if the segment rotation has left too much garbage.
+func (d *Disk) update(f func() error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	err := f()
+	if d.rotated && !d.compacting && d.garbage > d.size/2 {
+		d.background.Add(1)
+		go d.autoCompact()
+	}
+	d.rotated = false
+
+	return err
+}
+
+// compacts the log until garbage, including writes made during compaction,
+// is below half of the log size. Errors are logged, writes do not fail.
+func (d *Disk) autoCompact() {
+	defer d.background.Done()
+
+	for {
+		if err := d.Compact(); err != nil {
+			slog.Warn("failed to compact log", "dir", d.dir, "error", err)
+			return
+		}
+
+		d.mu.RLock()
+		again := len(d.segments) > 0 && !d.compacting && d.garbage > d.size/2
+		d.mu.RUnlock()
+
+		if !again {
+			return
+		}
+	}
+}
+
+// Compact rewrites live records into new segments and removes old segments.
+// Reads and writes are not blocked while records are copied, it returns
+// immediately if the compaction is already running.
+func (d *Disk) Compact() error {
+	c, err := d.plan()
+	if err != nil || c == nil {
+		return err
+	}
+
+	err = d.copy(c)
+
+	return d.commit(c, err)
+}
+
+// Close waits for background compaction and closes segment files.
+func (d *Disk) Close() error {
+	d.background.Wait()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var errs []error
+	for id, fd := range d.segments {
+		errs = append(errs, fd.Close())
+		delete(d.segments, id)
+	}
+
+	return errors.Join(errs...)
+}
+
+// open segments and rebuild the index
+func (d *Disk) open() error {
+	ids, err := d.list()
+	if err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		fd, err := os.OpenFile(d.path(id), os.O_RDWR, 0644)
+		if err != nil {
+			return err
+		}
+		d.segments[id] = fd
+
+		offset, err := d.replay(id, fd)
+		switch {
+		case errors.Is(err, errTornRecord):
+			// discard torn write at the tail of the segment, the process
+			// might crash before the segment is synced on rotation.
+			slog.Warn("discarding torn record", "segment", d.path(id), "offset", offset)
+			if err := fd.Truncate(offset); err != nil {
+				return err
+			}
+			if err := fd.Sync(); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		}
+
+		if i == len(ids)-1 {
+			d.active, d.offset = id, offset
+		}
+	}
+
+	if len(ids) == 0 {
+		return d.rotate()
+	}
+
+	return nil
+}
+
+// list identities of segments in ascending order
+func (d *Disk) list() ([]int, error) {
+	files, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0)
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		id, err := strconv.Atoi(strings.TrimSuffix(name, segmentExt))
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func (d *Disk) path(id int) string {
+	return filepath.Join(d.dir, fmt.Sprintf("%09d%s", id, segmentExt))
+}
+
+// replay records of segment into the index, it returns offset of the end
+// of the last valid record.
+func (d *Disk) replay(id int, fd *os.File) (int64, error) {
+	stat, err := fd.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	r := bufio.NewReaderSize(io.NewSectionReader(fd, 0, stat.Size()), 64<<10)
+	head := make([]byte, recordHeaderSize)
+	offset := int64(0)
+
+	for {
+		if _, err := io.ReadFull(r, head); err != nil {
+			if err == io.EOF {
+				return offset, nil
+			}
+			if err == io.ErrUnexpectedEOF {
+				return offset, errTornRecord
+			}
+			return offset, err
+		}
+
+		klen := int(binary.LittleEndian.Uint32(head[5:9]))
+		vlen := int(binary.LittleEndian.Uint32(head[9:13]))
+		if offset+int64(recordHeaderSize+klen+vlen) > stat.Size() {
+			return offset, errTornRecord
+		}
+
+		body := make([]byte, klen+vlen)
+		if _, err := io.ReadFull(r, body); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return offset, errTornRecord
+			}
+			return offset, err
+		}
+
+		hash := crc32.Update(crc32.Checksum(head[4:], crc32c), crc32c, body)
+		if hash != binary.LittleEndian.Uint32(head[0:4]) {
+			return offset, errTornRecord
+		}
+
+		size := int64(recordHeaderSize + klen + vlen)
+		key := string(body[:klen])
+		d.drop(key)
+		if head[4]&recordTombstone != 0 {
+			d.garbage += size
+		} else {
+			d.index[key] = location{
+				segment: id,
+				offset:  offset + recordHeaderSize + int64(klen),
+				length:  vlen,
+			}
+		}
+
+		d.size += size
+		offset += size
+	}
+}
+
+// drop the key from the index, the record becomes garbage
+func (d *Disk) drop(key string) {
+	if loc, has := d.index[key]; has {
+		d.garbage += int64(recordHeaderSize + len(key) + loc.length)
+		delete(d.index, key)
+	}
+}
+
+// append the record to the active segment and update the index
+func (d *Disk) append(flags byte, key, val []byte) error {
+	if d.offset >= d.confSegmentSize {
+		if err := d.rotate(); err != nil {
+			return err
+		}
+		d.rotated = true
+	}
+
+	at := d.offset
+	size, err := d.write(flags, key, val)
+	if err != nil {
+		return err
+	}
+
+	if d.confSync {
+		if err := d.segments[d.active].Sync(); err != nil {
+			return err
+		}
+	}
+
+	d.drop(string(key))
+	if flags&recordTombstone != 0 {
+		d.garbage += size
+	} else {
+		d.index[string(key)] = location{
+			segment: d.active,
+			offset:  at + recordHeaderSize + int64(len(key)),
+			length:  len(val),
+		}
+	}
+
+	return nil
+}
+
+// write the record to the active segment
+func (d *Disk) write(flags byte, key, val []byte) (int64, error) {
+	b := record(flags, key, val)
+	if _, err := d.segments[d.active].WriteAt(b, d.offset); err != nil {
+		return 0, err
+	}
+
+	size := int64(len(b))
+	d.size += size
+	d.offset += size
+
+	return size, nil
+}
+
+// encode the record
+func record(flags byte, key, val []byte) []byte {
+	b := make([]byte, recordHeaderSize+len(key)+len(val))
+	b[4] = flags
+	binary.LittleEndian.PutUint32(b[5:9], uint32(len(key)))
+	binary.LittleEndian.PutUint32(b[9:13], uint32(len(val)))
+	copy(b[recordHeaderSize:], key)
+	copy(b[recordHeaderSize+len(key):], val)
+	binary.LittleEndian.PutUint32(b[0:4], crc32.Checksum(b[4:], crc32c))
+	return b
+}
+
+// seal the active segment and create new one
+func (d *Disk) rotate() error {
+	id := d.active + 1
+	if len(d.segments) == 0 {
+		id = 0
+	}
+
+	return d.rotateTo(id)
+}
+
+// seal the active segment and create new one with the id
+func (d *Disk) rotateTo(id int) error {
+	if fd, has := d.segments[d.active]; has {
+		if err := fd.Sync(); err != nil {
+			return err
+		}
+	}
+
+	fd, err := d.create(id)
+	if err != nil {
+		return err
+	}
+
+	d.segments[id] = fd
+	d.active, d.offset = id, 0
+
+	return nil
+}
+
+// create segment file, the directory is synced so that the file survives
+// power loss.
+func (d *Disk) create(id int) (*os.File, error) {
+	fd, err := os.OpenFile(d.path(id), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syncDir(d.dir); err != nil {
+		fd.Close()
+		return nil, err
+	}
+
+	return fd, nil
+}
+
+func syncDir(dir string) error {
+	fd, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	return fd.Sync()
+}
+
+// compaction of the log. Live records are copied from old segments into
+// segments with ids reserved between old segments and the active one, so
+// that writes made during compaction are replayed after copied records.
+type compaction struct {
+	limit   int64
+	size    int64
+	keys    []string
+	index   map[string]location
+	old     []int
+	files   map[int]*os.File
+	base    int
+	created map[int]*os.File
+	moved   map[string]location
+	written int64
+}
+
+// plan the compaction: seal the active segment, snapshot live records and
+// reserve ids of compacted segments.
+func (d *Disk) plan() (*compaction, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.compacting {
+		return nil, nil
+	}
+
+	if len(d.segments) == 0 {
+		return nil, os.ErrClosed
+	}
+
+	c := &compaction{
+		limit:   d.confSegmentSize,
+		keys:    make([]string, 0, len(d.index)),
+		index:   make(map[string]location, len(d.index)),
+		old:     make([]int, 0, len(d.segments)),
+		files:   make(map[int]*os.File, len(d.segments)),
+		created: make(map[int]*os.File),
+		moved:   make(map[string]location, len(d.index)),
+	}
+
+	for id, fd := range d.segments {
+		c.old = append(c.old, id)
+		c.files[id] = fd
+	}
+	sort.Ints(c.old)
+
+	for key, loc := range d.index {
+		c.keys = append(c.keys, key)
+		c.index[key] = loc
+	}
+	sort.Slice(c.keys, func(i, j int) bool {
+		a, b := c.index[c.keys[i]], c.index[c.keys[j]]
+		return a.segment < b.segment || (a.segment == b.segment && a.offset < b.offset)
+	})
+
+	// segments required for live records, the size limit is applied as
+	// the log is written.
+	n, offset := 0, int64(0)
+	for i, key := range c.keys {
+		if i == 0 || offset >= d.confSegmentSize {
+			n, offset = n+1, 0
+		}
+		offset += int64(recordHeaderSize + len(key) + c.index[key].length)
+	}
+
+	c.base = d.active + 1
+	if err := d.rotateTo(c.base + n); err != nil {
+		return nil, err
+	}
+	c.size = d.size
+
+	d.compacting = true
+	return c, nil
+}
+
+// copy live records into compacted segments, old segments are sealed and
+// they are read without lock.
+func (d *Disk) copy(c *compaction) error {
+	var (
+		fd     *os.File
+		id     = c.base - 1
+		offset = int64(0)
+	)
+
+	for i, key := range c.keys {
+		if i == 0 || offset >= c.limit {
+			if fd != nil {
+				if err := fd.Sync(); err != nil {
+					return err
+				}
+			}
+
+			id, offset = id+1, 0
+			f, err := d.create(id)
+			if err != nil {
+				return err
+			}
+			fd = f
+			c.created[id] = fd
+		}
+
+		loc := c.index[key]
+		val := make([]byte, loc.length)
+		if _, err := c.files[loc.segment].ReadAt(val, loc.offset); err != nil {
+			return err
+		}
+
+		b := record(0, []byte(key), val)
+		if _, err := fd.WriteAt(b, offset); err != nil {
+			return err
+		}
+
+		c.moved[key] = location{
+			segment: id,
+			offset:  offset + recordHeaderSize + int64(len(key)),
+			length:  len(val),
+		}
+		offset += int64(len(b))
+		c.written += int64(len(b))
+	}
+
+	if fd != nil {
+		return fd.Sync()
+	}
+
+	return nil
+}
+
+// commit the compaction: records that are not changed during compaction are
+// moved to compacted segments, old segments are removed in ascending order,
+// so that the log stays consistent if the process crashes in the middle.
+func (d *Disk) commit(c *compaction, err error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.compacting = false
+
+	if err == nil && len(d.segments) == 0 {
+		err = os.ErrClosed
+	}
+
+	if err != nil {
+		for id, fd := range c.created {
+			fd.Close()
+			os.Remove(d.path(id))
+		}
+		return errors.Join(err, syncDir(d.dir))
+	}
+
+	for id, fd := range c.created {
+		d.segments[id] = fd
+	}
+
+	for key, loc := range c.moved {
+		if d.index[key] == c.index[key] {
+			d.index[key] = loc
+		}
+	}
+
+	// records changed during compaction are garbage in compacted segments
+	d.size += c.written - c.size
+	d.garbage += c.written - c.size
+
+	for _, id := range c.old {
+		if err := d.segments[id].Close(); err != nil {
+			return err
+		}
+		delete(d.segments, id)
+
+		if err := os.Remove(d.path(id)); err != nil {
+			return err
+		}
+	}
+
+	return syncDir(d.dir)
+}
@@ -0,0 +1,250 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio"
+)
+
+func TestDisk(t *testing.T) {
+	t.Run("GetPut", func(t *testing.T) {
+		db, err := aio.OpenDisk(t.TempDir())
+		it.Then(t).Should(it.Nil(err))
+		defer db.Close()
+
+		err = db.Put([]byte("a"), []byte("1"))
+		a, erra := db.Get([]byte("a"))
+		b, errb := db.Get([]byte("b"))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(erra),
+			it.Nil(errb),
+			it.Equal(string(a), "1"),
+			it.Equal(len(b), 0),
+		)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		db, _ := aio.OpenDisk(t.TempDir())
+		db.Sync(true)
+		defer db.Close()
+
+		err := db.BatchPut([][]byte{[]byte("a"), []byte("b")}, [][]byte{[]byte("1"), []byte("2")})
+		vals, errb := db.BatchGet([][]byte{[]byte("b"), []byte("c"), []byte("a")})
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(errb),
+			it.Equal(string(vals[0]), "2"),
+			it.Equal(len(vals[1]), 0),
+			it.Equal(string(vals[2]), "1"),
+		)
+	})
+
+	t.Run("Reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		db, _ := aio.OpenDisk(dir)
+		db.Put([]byte("a"), []byte("1"))
+		db.Put([]byte("b"), []byte("2"))
+		db.Put([]byte("a"), []byte("3"))
+		db.Delete([]byte("b"))
+		db.Close()
+
+		db, err := aio.OpenDisk(dir)
+		it.Then(t).Should(it.Nil(err))
+		defer db.Close()
+
+		a, _ := db.Get([]byte("a"))
+		b, _ := db.Get([]byte("b"))
+		it.Then(t).Should(
+			it.Equal(string(a), "3"),
+			it.Equal(len(b), 0),
+		)
+	})
+
+	t.Run("TornWrite", func(t *testing.T) {
+		dir := t.TempDir()
+		db, _ := aio.OpenDisk(dir)
+		db.Put([]byte("a"), []byte("1"))
+		db.Close()
+
+		file := filepath.Join(dir, "000000000.seg")
+		stat, _ := os.Stat(file)
+		fd, _ := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0644)
+		fd.Write([]byte{1, 2, 3, 4, 0, 1, 0, 0, 0, 255})
+		fd.Close()
+
+		db, err := aio.OpenDisk(dir)
+		it.Then(t).Should(it.Nil(err))
+
+		a, _ := db.Get([]byte("a"))
+		truncated, _ := os.Stat(file)
+		db.Put([]byte("b"), []byte("2"))
+		db.Close()
+
+		db, err = aio.OpenDisk(dir)
+		it.Then(t).Should(it.Nil(err))
+		defer db.Close()
+
+		b, _ := db.Get([]byte("b"))
+		it.Then(t).Should(
+			it.Equal(string(a), "1"),
+			it.Equal(string(b), "2"),
+			it.Equal(truncated.Size(), stat.Size()),
+		)
+	})
+
+	t.Run("TornSegment", func(t *testing.T) {
+		dir := t.TempDir()
+		db, _ := aio.OpenDisk(dir)
+		db.SegmentSize(1)
+		db.Put([]byte("a"), []byte("1"))
+		db.Put([]byte("b"), []byte("2"))
+		db.Close()
+
+		fd, _ := os.OpenFile(filepath.Join(dir, "000000000.seg"), os.O_APPEND|os.O_WRONLY, 0644)
+		fd.Write([]byte{1, 2, 3, 4, 0, 1, 0, 0, 0, 255})
+		fd.Close()
+
+		db, err := aio.OpenDisk(dir)
+		it.Then(t).Should(it.Nil(err))
+		defer db.Close()
+
+		a, _ := db.Get([]byte("a"))
+		b, _ := db.Get([]byte("b"))
+		it.Then(t).Should(
+			it.Equal(string(a), "1"),
+			it.Equal(string(b), "2"),
+		)
+	})
+
+	t.Run("Compact", func(t *testing.T) {
+		dir := t.TempDir()
+		db, _ := aio.OpenDisk(dir)
+		for i := 0; i < 100; i++ {
+			db.Put([]byte(strconv.Itoa(i%10)), []byte(strconv.Itoa(i)))
+		}
+		before := du(dir)
+
+		err := db.Compact()
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Less(du(dir), before),
+		)
+
+		db.Put([]byte("x"), []byte("y"))
+		db.Close()
+
+		db, err = aio.OpenDisk(dir)
+		it.Then(t).Should(it.Nil(err))
+		defer db.Close()
+
+		for i := 0; i < 10; i++ {
+			v, _ := db.Get([]byte(strconv.Itoa(i)))
+			it.Then(t).Should(it.Equal(string(v), strconv.Itoa(90+i)))
+		}
+		x, _ := db.Get([]byte("x"))
+		it.Then(t).Should(it.Equal(string(x), "y"))
+	})
+
+	t.Run("CompactConcurrent", func(t *testing.T) {
+		dir := t.TempDir()
+		db, _ := aio.OpenDisk(dir)
+		db.SegmentSize(1024)
+		for i := 0; i < 1000; i++ {
+			db.Put([]byte(strconv.Itoa(i%100)), []byte(strconv.Itoa(i)))
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				db.Put([]byte(strconv.Itoa(i)), []byte("x"+strconv.Itoa(i)))
+			}
+		}()
+
+		err := db.Compact()
+		<-done
+		db.Close()
+		it.Then(t).Should(it.Nil(err))
+
+		db, err = aio.OpenDisk(dir)
+		it.Then(t).Should(it.Nil(err))
+		defer db.Close()
+
+		for i := 0; i < 100; i++ {
+			v, _ := db.Get([]byte(strconv.Itoa(i)))
+			it.Then(t).Should(it.Equal(string(v), "x"+strconv.Itoa(i)))
+		}
+	})
+
+	t.Run("AutoCompact", func(t *testing.T) {
+		dir := t.TempDir()
+		db, _ := aio.OpenDisk(dir)
+		db.SegmentSize(256)
+
+		for i := 0; i < 1000; i++ {
+			err := db.Put([]byte(strconv.Itoa(i%4)), []byte(strconv.Itoa(i)))
+			it.Then(t).Should(it.Nil(err))
+		}
+		db.Close()
+
+		files, _ := os.ReadDir(dir)
+		it.Then(t).Should(
+			it.Less(len(files), 10),
+		)
+
+		db, err := aio.OpenDisk(dir)
+		it.Then(t).Should(it.Nil(err))
+		defer db.Close()
+
+		for i := 0; i < 4; i++ {
+			v, _ := db.Get([]byte(strconv.Itoa(i)))
+			it.Then(t).Should(it.Equal(string(v), strconv.Itoa(996+i)))
+		}
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		dir := t.TempDir()
+		db, _ := aio.OpenDisk(dir)
+		api := &counter{mock: mockVector()}
+		aio.NewCache(db, api).Embedding(context.Background(), "hello world")
+		db.Close()
+
+		db, _ = aio.OpenDisk(dir)
+		defer db.Close()
+		v, err := aio.NewCache(db, api).Embedding(context.Background(), "hello world")
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(api.calls, 1),
+			it.Seq(v.Vector).Equal(mockVector().reply.Vector...),
+		)
+	})
+}
+
+// disk usage of the directory
+func du(dir string) int64 {
+	size := int64(0)
+	files, _ := os.ReadDir(dir)
+	for _, f := range files {
+		if info, err := f.Info(); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
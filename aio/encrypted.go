@@ -0,0 +1,347 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// KeyProvider supplies encryption keys identified by id. Keys are AES-128,
+// AES-192 or AES-256 keys of 16, 24 or 32 bytes. The id must not be reused
+// for different keys.
+type KeyProvider interface {
+	// Current returns the key used to encrypt new values.
+	Current() (id uint32, key []byte, err error)
+
+	// Key returns the key of id used to decrypt values, it returns nil if
+	// the key is unknown (e.g. retired).
+	Key(id uint32) ([]byte, error)
+}
+
+// Keyring is in-memory KeyProvider, it is safe for concurrent use.
+type Keyring struct {
+	mu      sync.RWMutex
+	current uint32
+	keys    map[uint32][]byte
+}
+
+var _ KeyProvider = (*Keyring)(nil)
+
+// Creates keyring with the current key.
+func NewKeyring(id uint32, key []byte) *Keyring {
+	k := &Keyring{keys: make(map[uint32][]byte)}
+	k.Rotate(id, key)
+	return k
+}
+
+// Rotate makes the key current, previous keys remain available to decrypt
+// values until they are retired.
+func (k *Keyring) Rotate(id uint32, key []byte) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.current = id
+	k.keys[id] = append([]byte(nil), key...)
+}
+
+// Retire removes the key, values encrypted by it become cache misses.
+// The current key cannot be retired.
+func (k *Keyring) Retire(id uint32) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if id != k.current {
+		delete(k.keys, id)
+	}
+}
+
+// Current returns copy of the key used to encrypt new values.
+func (k *Keyring) Current() (uint32, []byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.current, bytes.Clone(k.keys[k.current]), nil
+}
+
+// Key returns copy of the key of id, nil if it is unknown.
+func (k *Keyring) Key(id uint32) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return bytes.Clone(k.keys[id]), nil
+}
+
+// The encrypted value is binary format (little endian):
+//
+//	version    uint8
+//	key id     uint32
+//	nonce      [12]byte
+//	ciphertext [...]byte (AES-GCM sealed value and tag)
+//
+// The header and the storage key are authenticated, the value cannot be
+// moved to another key. The storage key is HMAC-SHA256 of the key.
+const (
+	sealedVersion    = 1
+	sealedHeaderSize = 1 + 4
+	sealedNonceSize  = 12
+)
+
+// Encrypted is KeyVal that encrypts values using AES-GCM before they are
+// written to the underlying KeyVal. The id of the key is recorded in the
+// value, keys are rotated by KeyProvider without invalidating the cache.
+// Values that cannot be decrypted (unknown key, tampered value) are misses,
+// the cache overwrites them with values encrypted by the current key.
+// It is safe for concurrent use if the underlying KeyVal is.
+//
+// Keys are stored as HMAC-SHA256 of the key using the secret, keys of
+// the cache (hashes of texts) do not reveal known texts. Keep the secret
+// apart from the KeyVal.
+//
+//	db, err := aio.NewEncrypted(aio.NewMemory(10000), aio.NewKeyring(1, key), secret)
+//	text := aio.NewCache(db, cli)
+type Encrypted struct {
+	cache  KeyVal
+	keys   KeyProvider
+	secret []byte
+	mu     sync.Mutex
+	aeads  map[uint32]cipher.AEAD
+}
+
+var (
+	_ KeyVal      = (*Encrypted)(nil)
+	_ Deleter     = (*Encrypted)(nil)
+	_ BatchGetter = (*Encrypted)(nil)
+	_ BatchPutter = (*Encrypted)(nil)
+)
+
+// Creates encrypting KeyVal, the secret of storage keys is required.
+func NewEncrypted(cache KeyVal, keys KeyProvider, secret []byte) (*Encrypted, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("encrypted storage requires secret of keys")
+	}
+
+	return &Encrypted{
+		cache:  cache,
+		keys:   keys,
+		secret: bytes.Clone(secret),
+		aeads:  make(map[uint32]cipher.AEAD),
+	}, nil
+}
+
+// Get returns decrypted value of the key, nil if key is not found or
+// the value cannot be decrypted.
+func (e *Encrypted) Get(key []byte) ([]byte, error) {
+	key = e.storageKey(key)
+	val, err := e.cache.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.open(key, val)
+}
+
+// BatchGet returns decrypted values of keys, using batch api of
+// the underlying KeyVal if it supports it.
+func (e *Encrypted) BatchGet(keys [][]byte) ([][]byte, error) {
+	keys = e.storageKeys(keys)
+
+	var vals [][]byte
+	if kv, ok := e.cache.(BatchGetter); ok {
+		seq, err := kv.BatchGet(keys)
+		if err != nil {
+			return nil, err
+		}
+		if len(seq) != len(keys) {
+			return nil, fmt.Errorf("batch get has failed: expected %d values, got %d", len(keys), len(seq))
+		}
+		vals = seq
+	} else {
+		vals = make([][]byte, len(keys))
+		for i, key := range keys {
+			val, err := e.cache.Get(key)
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = val
+		}
+	}
+
+	for i, key := range keys {
+		val, err := e.open(key, vals[i])
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+
+	return vals, nil
+}
+
+// Put encrypts value of the key with the current key.
+func (e *Encrypted) Put(key []byte, val []byte) error {
+	key = e.storageKey(key)
+	sealed, err := e.seal(key, val)
+	if err != nil {
+		return err
+	}
+
+	return e.cache.Put(key, sealed)
+}
+
+// BatchPut encrypts values of keys with the current key, using batch api of
+// the underlying KeyVal if it supports it.
+func (e *Encrypted) BatchPut(keys, vals [][]byte) error {
+	if len(keys) != len(vals) {
+		return fmt.Errorf("batch put has failed: %d keys, %d values", len(keys), len(vals))
+	}
+
+	keys = e.storageKeys(keys)
+	seq := make([][]byte, len(vals))
+	for i, key := range keys {
+		sealed, err := e.seal(key, vals[i])
+		if err != nil {
+			return err
+		}
+		seq[i] = sealed
+	}
+
+	if kv, ok := e.cache.(BatchPutter); ok {
+		return kv.BatchPut(keys, seq)
+	}
+
+	for i, key := range keys {
+		if err := e.cache.Put(key, seq[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the key if the underlying KeyVal implements Deleter.
+func (e *Encrypted) Delete(key []byte) error {
+	if kv, ok := e.cache.(Deleter); ok {
+		return kv.Delete(e.storageKey(key))
+	}
+
+	return nil
+}
+
+// storage key is HMAC of the key, it does not reveal the key
+func (e *Encrypted) storageKey(key []byte) []byte {
+	mac := hmac.New(sha256.New, e.secret)
+	mac.Write(key)
+	return mac.Sum(nil)
+}
+
+func (e *Encrypted) storageKeys(keys [][]byte) [][]byte {
+	seq := make([][]byte, len(keys))
+	for i, key := range keys {
+		seq[i] = e.storageKey(key)
+	}
+	return seq
+}
+
+func (e *Encrypted) seal(key, val []byte) ([]byte, error) {
+	id, secret, err := e.keys.Current()
+	if err != nil {
+		return nil, fmt.Errorf("encryption has failed: %w", err)
+	}
+
+	aead, err := e.aead(id, secret)
+	if err != nil {
+		return nil, fmt.Errorf("encryption has failed: %w", err)
+	}
+
+	b := make([]byte, sealedHeaderSize+sealedNonceSize, sealedHeaderSize+sealedNonceSize+len(val)+aead.Overhead())
+	b[0] = sealedVersion
+	binary.LittleEndian.PutUint32(b[1:sealedHeaderSize], id)
+
+	nonce := b[sealedHeaderSize:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("encryption has failed: %w", err)
+	}
+
+	return aead.Seal(b, nonce, val, additional(b[:sealedHeaderSize], key)), nil
+}
+
+// opens sealed value, the value that cannot be decrypted is a miss
+func (e *Encrypted) open(key, val []byte) ([]byte, error) {
+	if len(val) == 0 {
+		return nil, nil
+	}
+
+	if len(val) < sealedHeaderSize+sealedNonceSize || val[0] != sealedVersion {
+		slog.Warn("failed to decrypt cache value", "error", fmt.Errorf("%w: malformed", errCorrupted))
+		return nil, nil
+	}
+
+	id := binary.LittleEndian.Uint32(val[1:sealedHeaderSize])
+	secret, err := e.keys.Key(id)
+	if err != nil {
+		return nil, fmt.Errorf("decryption has failed: %w", err)
+	}
+	if secret == nil {
+		slog.Warn("failed to decrypt cache value", "error", fmt.Errorf("unknown key %d", id))
+		return nil, nil
+	}
+
+	aead, err := e.aead(id, secret)
+	if err != nil {
+		return nil, fmt.Errorf("decryption has failed: %w", err)
+	}
+
+	nonce := val[sealedHeaderSize : sealedHeaderSize+sealedNonceSize]
+	text := val[sealedHeaderSize+sealedNonceSize:]
+	plain, err := aead.Open(nil, nonce, text, additional(val[:sealedHeaderSize], key))
+	if err != nil {
+		slog.Warn("failed to decrypt cache value", "error", fmt.Errorf("%w: %w", errCorrupted, err))
+		return nil, nil
+	}
+
+	return plain, nil
+}
+
+// returns cipher of the key, ciphers are reused by key id
+func (e *Encrypted) aead(id uint32, secret []byte) (cipher.AEAD, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if aead, has := e.aeads[id]; has {
+		return aead, nil
+	}
+
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	e.aeads[id] = aead
+	return aead, nil
+}
+
+// additional authenticated data of the value
+func additional(header, key []byte) []byte {
+	return append(append(make([]byte, 0, len(header)+len(key)), header...), key...)
+}
@@ -0,0 +1,186 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio"
+)
+
+func TestEncrypted(t *testing.T) {
+	k1 := bytes.Repeat([]byte{1}, 32)
+	k2 := bytes.Repeat([]byte{2}, 16)
+
+	t.Run("PutGet", func(t *testing.T) {
+		kv := keyval{}
+		db := encrypted(t, kv, aio.NewKeyring(1, k1))
+
+		err := db.Put([]byte("a"), []byte("plain text"))
+		val, _ := db.Get([]byte("a"))
+		none, _ := db.Get([]byte("b"))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(string(val), "plain text"),
+			it.Equal(len(none), 0),
+		).ShouldNot(
+			it.True(bytes.Contains(kv[stored("a")], []byte("plain text"))),
+		)
+	})
+
+	t.Run("Keyring", func(t *testing.T) {
+		keys := aio.NewKeyring(1, k1)
+		_, key, _ := keys.Current()
+		key[0] = 0xff
+		old, _ := keys.Key(1)
+		old[1] = 0xff
+
+		_, cur, _ := keys.Current()
+		it.Then(t).Should(
+			it.Seq(cur).Equal(k1...),
+		)
+	})
+
+	t.Run("Rotation", func(t *testing.T) {
+		kv := keyval{}
+		keys := aio.NewKeyring(1, k1)
+		db := encrypted(t, kv, keys)
+
+		db.Put([]byte("a"), []byte("old"))
+		keys.Rotate(2, k2)
+		db.Put([]byte("b"), []byte("new"))
+
+		a, _ := db.Get([]byte("a"))
+		b, _ := db.Get([]byte("b"))
+		it.Then(t).Should(
+			it.Equal(string(a), "old"),
+			it.Equal(string(b), "new"),
+		)
+
+		keys.Retire(1)
+		keys.Retire(2)
+		a, erra := db.Get([]byte("a"))
+		b, _ = db.Get([]byte("b"))
+		it.Then(t).Should(
+			it.Nil(erra),
+			it.Equal(len(a), 0),
+			it.Equal(string(b), "new"),
+		)
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		kv := keyval{}
+		db := encrypted(t, kv, aio.NewKeyring(1, k1))
+
+		db.Put([]byte("a"), []byte("value"))
+		kv[stored("a")][len(kv[stored("a")])-1] ^= 0xff
+		a, erra := db.Get([]byte("a"))
+
+		// value is bound to the key
+		db.Put([]byte("b"), []byte("value"))
+		kv[stored("c")] = kv[stored("b")]
+		c, errc := db.Get([]byte("c"))
+
+		it.Then(t).Should(
+			it.Nil(erra),
+			it.Equal(len(a), 0),
+			it.Nil(errc),
+			it.Equal(len(c), 0),
+		)
+	})
+
+	t.Run("InvalidKey", func(t *testing.T) {
+		db := encrypted(t, keyval{}, aio.NewKeyring(1, []byte("short")))
+
+		it.Then(t).ShouldNot(
+			it.Nil(db.Put([]byte("a"), []byte("value"))),
+		)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		kv := aio.NewMemory(10)
+		db := encrypted(t, kv, aio.NewKeyring(1, k1))
+
+		err := db.BatchPut([][]byte{[]byte("a"), []byte("b")}, [][]byte{[]byte("1"), []byte("2")})
+		vals, _ := db.BatchGet([][]byte{[]byte("a"), []byte("c"), []byte("b")})
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(vals), 3),
+			it.Equal(string(vals[0]), "1"),
+			it.Equal(len(vals[1]), 0),
+			it.Equal(string(vals[2]), "2"),
+		)
+	})
+
+	t.Run("NoSecret", func(t *testing.T) {
+		_, err := aio.NewEncrypted(keyval{}, aio.NewKeyring(1, k1), nil)
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("StorageKey", func(t *testing.T) {
+		kv := keyval{}
+		c := aio.NewCache(encrypted(t, kv, aio.NewKeyring(1, k1)), mockVector())
+		c.Embedding(context.Background(), "hello world")
+
+		sum := sha1.Sum([]byte("hello world"))
+		_, has := kv[string(sum[:])]
+		it.Then(t).Should(
+			it.Equal(len(kv), 1),
+			it.True(len(kv[stored(string(sum[:]))]) > 0),
+		).ShouldNot(
+			it.True(has),
+		)
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		kv := keyval{}
+		api := &counter{mock: mockVector()}
+		c := aio.NewCache(encrypted(t, kv, aio.NewKeyring(1, k1)), api)
+
+		a, erra := c.Embedding(context.Background(), "hello world")
+		b, errb := c.Embedding(context.Background(), "hello world")
+
+		it.Then(t).Should(
+			it.Nil(erra),
+			it.Nil(errb),
+			it.Equal(api.calls, 1),
+			it.Equal(len(kv), 1),
+			it.Seq(b.Vector).Equal(a.Vector...),
+		)
+	})
+}
+
+var secret = []byte("secret")
+
+func encrypted(t *testing.T, kv aio.KeyVal, keys aio.KeyProvider) *aio.Encrypted {
+	t.Helper()
+
+	db, err := aio.NewEncrypted(kv, keys, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// storage key of the encrypted KeyVal
+func stored(key string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(key))
+	return string(mac.Sum(nil))
+}
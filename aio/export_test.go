@@ -0,0 +1,26 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio
+
+import "time"
+
+// SetClock replaces the clock of memory, it is used to expire entries
+// without waiting.
+func (m *Memory) SetClock(clock func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clock = clock
+}
+
+// OnWait registers the callback called when the request waits for
+// the concurrent request of the same text.
+func (c *Cache) OnWait(f func()) {
+	c.flight.testHookWait = f
+}
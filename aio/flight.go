@@ -0,0 +1,118 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kshard/embeddings"
+)
+
+// flight coalesces concurrent calls for the same key, only the first caller
+// executes the function while others wait for its result.
+type flight struct {
+	mu    sync.Mutex
+	calls map[string]*call
+
+	// called when caller waits for the call in flight, used by tests
+	testHookWait func()
+}
+
+// call in flight
+type call struct {
+	done      chan struct{}
+	reply     embeddings.Embedding
+	err       error
+	cancelled bool
+}
+
+func newFlight() *flight {
+	return &flight{calls: make(map[string]*call)}
+}
+
+// do executes the function once for concurrent callers of the key, the reply
+// is shared with waiters. Waiters stop waiting if their context is cancelled.
+// The call failed due to cancelled context of the first caller is retried by
+// waiters. The panic of function is reported to waiters as error.
+func (f *flight) do(ctx context.Context, key string, fn func() (embeddings.Embedding, error)) (reply embeddings.Embedding, shared bool, err error) {
+	for {
+		c, leader := f.begin(key)
+		if leader {
+			reply, err = f.lead(ctx, key, c, fn)
+			return reply, false, err
+		}
+
+		reply, done, err := f.wait(ctx, c)
+		if done {
+			return reply, true, err
+		}
+	}
+}
+
+// begin the call of the key, the caller leads the call unless another caller
+// has the call in flight.
+func (f *flight) begin(key string) (*call, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if c, has := f.calls[key]; has {
+		return c, false
+	}
+
+	c := &call{done: make(chan struct{})}
+	f.calls[key] = c
+	return c, true
+}
+
+// end the call led by the caller, the reply is shared with waiters.
+func (f *flight) end(key string, c *call, reply embeddings.Embedding, err error, cancelled bool) {
+	c.reply, c.err, c.cancelled = reply, err, cancelled
+
+	f.mu.Lock()
+	delete(f.calls, key)
+	f.mu.Unlock()
+
+	close(c.done)
+}
+
+// lead the call executing the function
+func (f *flight) lead(ctx context.Context, key string, c *call, fn func() (embeddings.Embedding, error)) (reply embeddings.Embedding, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			f.end(key, c, embeddings.Embedding{}, fmt.Errorf("embedding has panicked: %v", r), false)
+			panic(r)
+		}
+	}()
+
+	reply, err = fn()
+	f.end(key, c, reply, err, err != nil && ctx.Err() != nil)
+	return reply, err
+}
+
+// wait for the call led by another caller, it returns false if the call is
+// cancelled by context of its leader and it has to be retried.
+func (f *flight) wait(ctx context.Context, c *call) (embeddings.Embedding, bool, error) {
+	if f.testHookWait != nil {
+		f.testHookWait()
+	}
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return embeddings.Embedding{}, true, ctx.Err()
+	}
+
+	if c.cancelled && ctx.Err() == nil {
+		return embeddings.Embedding{}, false, nil
+	}
+
+	return c.reply, true, c.err
+}
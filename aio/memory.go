@@ -0,0 +1,212 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Memory is in-memory KeyVal with LRU eviction, it is safe for concurrent use.
+// The size of memory is bounded by number of entries, use MaxBytes method to
+// bound it by size of keys and values in bytes too. Entries expire after
+// time-to-live if it is defined by TTL method.
+//
+//	text := aio.NewCache(aio.NewMemory(10000), cli)
+type Memory struct {
+	mu           sync.Mutex
+	confEntries  int
+	confMaxBytes int
+	confTTL      time.Duration
+	clock        func() time.Time
+	items        map[string]*list.Element
+	lru          *list.List
+	bytes        int
+	stats        MemoryStats
+}
+
+var (
+	_ KeyVal      = (*Memory)(nil)
+	_ Deleter     = (*Memory)(nil)
+	_ BatchGetter = (*Memory)(nil)
+	_ BatchPutter = (*Memory)(nil)
+)
+
+// MemoryStats is statistics of Memory usage.
+type MemoryStats struct {
+	Entries     int
+	Bytes       int
+	Hits        int
+	Misses      int
+	Evictions   int
+	Expirations int
+}
+
+// entry of lru list
+type entry struct {
+	key     string
+	val     []byte
+	expires time.Time
+}
+
+// Creates in-memory KeyVal bounded by number of entries, 0 is unbounded.
+func NewMemory(entries int) *Memory {
+	return &Memory{
+		confEntries: max(entries, 0),
+		clock:       time.Now,
+		items:       make(map[string]*list.Element),
+		lru:         list.New(),
+	}
+}
+
+// MaxBytes bounds the size of keys and values in bytes.
+// The default value is 0, the size is unbounded.
+func (m *Memory) MaxBytes(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.confMaxBytes = max(n, 0)
+	m.evict()
+}
+
+// TTL defines time-to-live of entries.
+// The default value is 0, entries never expire.
+func (m *Memory) TTL(ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.confTTL = max(ttl, 0)
+}
+
+// Stats returns statistics of memory usage.
+func (m *Memory) Stats() MemoryStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.stats
+	stats.Entries = m.lru.Len()
+	stats.Bytes = m.bytes
+	return stats
+}
+
+// Get returns value of the key, it returns nil if key is not found.
+// The value must not be modified.
+func (m *Memory) Get(key []byte) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.get(key), nil
+}
+
+// BatchGet returns values of keys, nil if key is not found.
+func (m *Memory) BatchGet(keys [][]byte) ([][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vals := make([][]byte, len(keys))
+	for i, key := range keys {
+		vals[i] = m.get(key)
+	}
+
+	return vals, nil
+}
+
+func (m *Memory) get(key []byte) []byte {
+	el, has := m.items[string(key)]
+	if !has {
+		m.stats.Misses++
+		return nil
+	}
+
+	e := el.Value.(*entry)
+	if !e.expires.IsZero() && !m.clock().Before(e.expires) {
+		m.remove(el)
+		m.stats.Expirations++
+		m.stats.Misses++
+		return nil
+	}
+
+	m.lru.MoveToFront(el)
+	m.stats.Hits++
+	return e.val
+}
+
+// Put stores value of the key, the value is copied. Values larger than
+// the bytes limit are not stored.
+func (m *Memory) Put(key []byte, val []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(key, val)
+	return nil
+}
+
+// BatchPut stores values of keys, values are copied.
+func (m *Memory) BatchPut(keys, vals [][]byte) error {
+	if len(keys) != len(vals) {
+		return fmt.Errorf("batch put has failed: %d keys, %d values", len(keys), len(vals))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, key := range keys {
+		m.put(key, vals[i])
+	}
+	return nil
+}
+
+func (m *Memory) put(key []byte, val []byte) {
+	if el, has := m.items[string(key)]; has {
+		m.remove(el)
+	}
+
+	if m.confMaxBytes != 0 && len(key)+len(val) > m.confMaxBytes {
+		return
+	}
+
+	e := &entry{key: string(key), val: append([]byte(nil), val...)}
+	if m.confTTL != 0 {
+		e.expires = m.clock().Add(m.confTTL)
+	}
+
+	m.items[e.key] = m.lru.PushFront(e)
+	m.bytes += len(e.key) + len(e.val)
+	m.evict()
+}
+
+// Delete removes the key.
+func (m *Memory) Delete(key []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, has := m.items[string(key)]; has {
+		m.remove(el)
+	}
+
+	return nil
+}
+
+// evicts least recently used entries until limits are satisfied
+func (m *Memory) evict() {
+	for m.lru.Len() > 0 &&
+		((m.confEntries != 0 && m.lru.Len() > m.confEntries) ||
+			(m.confMaxBytes != 0 && m.bytes > m.confMaxBytes)) {
+		m.remove(m.lru.Back())
+		m.stats.Evictions++
+	}
+}
+
+func (m *Memory) remove(el *list.Element) {
+	e := m.lru.Remove(el).(*entry)
+	delete(m.items, e.key)
+	m.bytes -= len(e.key) + len(e.val)
+}
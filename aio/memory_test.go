@@ -0,0 +1,154 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio"
+)
+
+func TestMemory(t *testing.T) {
+	t.Run("GetPut", func(t *testing.T) {
+		m := aio.NewMemory(10)
+		err := m.Put([]byte("a"), []byte("1"))
+		a, erra := m.Get([]byte("a"))
+		b, errb := m.Get([]byte("b"))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(erra),
+			it.Nil(errb),
+			it.Equal(string(a), "1"),
+			it.Equal(len(b), 0),
+			it.Equal(m.Stats(), aio.MemoryStats{Entries: 1, Bytes: 2, Hits: 1, Misses: 1}),
+		)
+	})
+
+	t.Run("Entries", func(t *testing.T) {
+		m := aio.NewMemory(2)
+		m.Put([]byte("a"), []byte("1"))
+		m.Put([]byte("b"), []byte("2"))
+		m.Get([]byte("a"))
+		m.Put([]byte("c"), []byte("3"))
+
+		a, _ := m.Get([]byte("a"))
+		b, _ := m.Get([]byte("b"))
+		c, _ := m.Get([]byte("c"))
+
+		it.Then(t).Should(
+			it.Equal(string(a), "1"),
+			it.Equal(len(b), 0),
+			it.Equal(string(c), "3"),
+			it.Equal(m.Stats().Evictions, 1),
+		)
+	})
+
+	t.Run("MaxBytes", func(t *testing.T) {
+		m := aio.NewMemory(0)
+		m.MaxBytes(10)
+		m.Put([]byte("a"), []byte("1234"))
+		m.Put([]byte("b"), []byte("1234"))
+		m.Put([]byte("c"), []byte("1234"))
+		m.Put([]byte("d"), []byte("12345678901"))
+
+		a, _ := m.Get([]byte("a"))
+		d, _ := m.Get([]byte("d"))
+
+		it.Then(t).Should(
+			it.Equal(len(a), 0),
+			it.Equal(len(d), 0),
+			it.Equal(m.Stats().Entries, 2),
+			it.Equal(m.Stats().Bytes, 10),
+		)
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		now := time.Now()
+		m := aio.NewMemory(10)
+		m.SetClock(func() time.Time { return now })
+		m.TTL(10 * time.Millisecond)
+		m.Put([]byte("a"), []byte("1"))
+
+		a, _ := m.Get([]byte("a"))
+		now = now.Add(10 * time.Millisecond)
+		b, _ := m.Get([]byte("a"))
+
+		it.Then(t).Should(
+			it.Equal(string(a), "1"),
+			it.Equal(len(b), 0),
+			it.Equal(m.Stats().Expirations, 1),
+			it.Equal(m.Stats().Entries, 0),
+		)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		m := aio.NewMemory(10)
+		err := m.BatchPut([][]byte{[]byte("a"), []byte("b")}, [][]byte{[]byte("1"), []byte("2")})
+		vals, errb := m.BatchGet([][]byte{[]byte("b"), []byte("c"), []byte("a")})
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Nil(errb),
+			it.Equal(string(vals[0]), "2"),
+			it.Equal(len(vals[1]), 0),
+			it.Equal(string(vals[2]), "1"),
+		)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		m := aio.NewMemory(10)
+		m.Put([]byte("a"), []byte("1"))
+		m.Delete([]byte("a"))
+
+		a, _ := m.Get([]byte("a"))
+		it.Then(t).Should(
+			it.Equal(len(a), 0),
+			it.Equal(m.Stats().Bytes, 0),
+		)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		m := aio.NewMemory(16)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					key := []byte(strconv.Itoa(j % 32))
+					m.Put(key, key)
+					m.Get(key)
+				}
+			}()
+		}
+		wg.Wait()
+
+		it.Then(t).Should(
+			it.Equal(m.Stats().Entries, 16),
+		)
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		api := &counter{mock: mockVector()}
+		c := aio.NewCache(aio.NewMemory(10), api)
+		c.Embedding(context.Background(), "hello world")
+		c.Embedding(context.Background(), "hello world")
+
+		it.Then(t).Should(
+			it.Equal(api.calls, 1),
+		)
+	})
+}
@@ -10,6 +10,8 @@ package aio_test
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 
 	"github.com/kshard/embeddings"
 )
@@ -33,3 +35,97 @@ func (mock mock) UsedTokens() int { return mock.reply.UsedTokens }
 func (mock mock) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
 	return mock.reply, nil
 }
+
+// mock embedding client counting requests
+type counter struct {
+	mock
+	calls int
+	err   error
+}
+
+func (c *counter) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	c.calls++
+	if c.err != nil {
+		return embeddings.Embedding{}, c.err
+	}
+	return c.mock.Embedding(ctx, text)
+}
+
+// mock embedding client blocking requests until released or cancelled,
+// each request is signalled to called channel if it is defined.
+type blocking struct {
+	mock
+	calls   atomic.Int32
+	called  chan struct{}
+	release chan struct{}
+	err     error
+}
+
+func (b *blocking) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	b.calls.Add(1)
+	if b.called != nil {
+		b.called <- struct{}{}
+	}
+
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return embeddings.Embedding{}, ctx.Err()
+	}
+
+	if b.err != nil {
+		return embeddings.Embedding{}, b.err
+	}
+	return b.mock.Embedding(ctx, text)
+}
+
+// mock embedding client panicking once released
+type panicking struct{ blocking }
+
+func (p *panicking) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	p.blocking.Embedding(ctx, text)
+	panic("failed")
+}
+
+// mock embedding client with batch api, vector is the length of text.
+// Requests are signalled to called channel and blocked until released
+// if channels are defined.
+type batcher struct {
+	calls   int
+	texts   []string
+	called  chan struct{}
+	release chan struct{}
+	err     error
+}
+
+func (*batcher) UsedTokens() int { return 0 }
+func (b *batcher) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	return embeddings.Embedding{}, fmt.Errorf("not supported")
+}
+func (b *batcher) Embeddings(ctx context.Context, text []string) ([]embeddings.Embedding, error) {
+	b.calls++
+	b.texts = append(b.texts, text...)
+	if b.called != nil {
+		b.called <- struct{}{}
+	}
+	if b.release != nil {
+		<-b.release
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	seq := make([]embeddings.Embedding, len(text))
+	for i, x := range text {
+		seq[i] = embeddings.Embedding{Text: x, Vector: []float32{float32(len(x))}, UsedTokens: len(x)}
+	}
+	return seq, nil
+}
+
+// mock embedding client with fixed vector
+type fixed []float32
+
+func (fixed) UsedTokens() int { return 0 }
+func (v fixed) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	return embeddings.Embedding{Text: text, Vector: v}, nil
+}
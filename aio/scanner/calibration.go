@@ -0,0 +1,134 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/kshard/embeddings"
+	"github.com/kshard/embeddings/vector"
+)
+
+// Calibration recommends the similarity function for the embedding model.
+// The distribution of cosine distances differs per model (e.g. dimensions,
+// normalization), the fixed ranges like [HighSimilarity] are guesswork.
+// Calibration samples cosine distances of consecutive sentences from
+// the corpus through the embedder and derives the threshold either by
+// percentile or by target average chunk size.
+//
+// Call Sample for each document of the corpus, sentences of different
+// documents or sections are not paired. The number of sampled sentences
+// is limited, use SampleSize method to change default 1000 sentences value.
+type Calibration struct {
+	embed          embeddings.Embedder
+	confSampleSize int
+	confWorkers    int
+	sentences      int
+	distances      []float32
+}
+
+// Creates new instance of Calibration for the embedder.
+func NewCalibration(embed embeddings.Embedder) *Calibration {
+	return &Calibration{
+		embed:          embed,
+		confSampleSize: 1000,
+		confWorkers:    1,
+		distances:      make([]float32, 0),
+	}
+}
+
+// SampleSize defines the maximum number of sentences sampled from corpus.
+// The default value is 1000 sentences.
+func (c *Calibration) SampleSize(n int) {
+	c.confSampleSize = max(n, 2)
+}
+
+// Workers defines number of concurrent embedding requests.
+// The default value is 1, sentences are embedded sequentially.
+func (c *Calibration) Workers(n int) {
+	c.confWorkers = n
+}
+
+// Sample reads sentences of the document and records cosine distances of
+// consecutive sentences. It stops when the sample size is reached.
+func (c *Calibration) Sample(ctx context.Context, r Reader) error {
+	txt := make([]string, 0)
+	sec := make([]int, 0)
+
+	for c.sentences+len(txt) < c.confSampleSize && r.Scan() {
+		txt = append(txt, r.Text())
+		if x, ok := r.(Sectioner); ok {
+			sec = append(sec, x.Section().Index)
+		} else {
+			sec = append(sec, 0)
+		}
+	}
+
+	if err := r.Err(); err != nil {
+		return err
+	}
+
+	vec, err := prefetch(ctx, c.embed, c.confWorkers, txt)
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i < len(vec); i++ {
+		if sec[i] != sec[i-1] {
+			continue
+		}
+		d, err := vector.Cosine(vec[i-1], vec[i])
+		if err != nil {
+			return fmt.Errorf("calibration has failed: %w, for {%s}", err, txt[i])
+		}
+		c.distances = append(c.distances, d)
+	}
+	c.sentences += len(txt)
+
+	return nil
+}
+
+// Distances returns sorted cosine distances of sampled sentences.
+func (c *Calibration) Distances() []float32 {
+	seq := slices.Clone(c.distances)
+	slices.Sort(seq)
+	return seq
+}
+
+// Percentile returns cosine distance at the percentile p ∈ [0, 1] of
+// sampled distances (nearest rank). It returns 0 if nothing is sampled.
+func (c *Calibration) Percentile(p float64) float32 {
+	seq := c.Distances()
+	if len(seq) == 0 {
+		return 0
+	}
+
+	p = min(max(p, 0), 1)
+	at := int(math.Ceil(p*float64(len(seq)))) - 1
+	return seq[max(at, 0)]
+}
+
+// ByPercentile recommends the similarity function, consecutive sentences
+// with cosine distance below the percentile p ∈ [0, 1] are similar.
+// For example, 0.9 breaks the text at 10% the most distant pairs.
+func (c *Calibration) ByPercentile(p float64) func(a, b []float32) bool {
+	return RangeSimilarity(0, c.Percentile(p))
+}
+
+// ByChunkSize recommends the similarity function for the target average
+// chunk size in sentences. Chunk of n sentences has n-1 similar pairs and
+// breaks at 1 pair, therefore the threshold is the percentile 1 - 1/n.
+// The estimate is exact for the consecutive linkage ([SIMILARITY_WITH_TAIL])
+// when the context window does not limit chunks.
+func (c *Calibration) ByChunkSize(n float64) func(a, b []float32) bool {
+	return c.ByPercentile(1 - 1/max(n, 1))
+}
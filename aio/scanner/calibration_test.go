@@ -0,0 +1,99 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings"
+	"github.com/kshard/embeddings/aio/scanner"
+)
+
+func TestCalibration(t *testing.T) {
+	text := "Aaa. Aaa. Aaa. Bbb. Bbb. Bbb."
+
+	t.Run("Distances", func(t *testing.T) {
+		c := scanner.NewCalibration(topic{})
+		err := c.Sample(context.Background(), scanner.NewSentences(strings.NewReader(text)))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(c.Distances()).Equal(0, 0, 0, 0, 0.5),
+			it.Equal(c.Percentile(0.8), 0),
+			it.Equal(c.Percentile(1.0), 0.5),
+		)
+	})
+
+	t.Run("SampleSize", func(t *testing.T) {
+		c := scanner.NewCalibration(topic{})
+		c.SampleSize(4)
+		err := c.Sample(context.Background(), scanner.NewSentences(strings.NewReader(text)))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(c.Distances()).Equal(0, 0, 0.5),
+		)
+	})
+
+	t.Run("Sections", func(t *testing.T) {
+		doc := "# A\n\nAaa. Aaa.\n\n# B\n\nBbb. Bbb.\n"
+		c := scanner.NewCalibration(topic{})
+		err := c.Sample(context.Background(), scanner.NewMarkdown(strings.NewReader(doc)))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Seq(c.Distances()).Equal(0, 0),
+		)
+	})
+
+	t.Run("ByChunkSize", func(t *testing.T) {
+		c := scanner.NewCalibration(topic{})
+		err := c.Sample(context.Background(), scanner.NewSentences(strings.NewReader(text)))
+		it.Then(t).Should(it.Nil(err))
+
+		s := scanner.New(topic{}, scanner.NewSentences(strings.NewReader(text)))
+		s.Similarity(c.ByChunkSize(3))
+
+		it.Then(t).Should(
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("Aaa.", "Aaa.", "Aaa."),
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("Bbb.", "Bbb.", "Bbb."),
+		)
+
+		it.Then(t).ShouldNot(
+			it.True(s.Scan()),
+		)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		c := scanner.NewCalibration(topic{})
+		err := c.Sample(context.Background(), scanner.NewSentences(strings.NewReader("")))
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(c.Percentile(0.5), 0),
+		)
+	})
+}
+
+// embeds sentences starting with A and B into orthogonal vectors
+type topic struct{}
+
+func (topic) UsedTokens() int { return 0 }
+func (topic) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	v := []float32{1, 0, 0, 0}
+	if strings.HasPrefix(text, "B") {
+		v = []float32{0, 1, 0, 0}
+	}
+	return embeddings.Embedding{Text: text, Vector: v}, nil
+}
@@ -0,0 +1,155 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+import (
+	"strconv"
+
+	"github.com/kshard/embeddings/vector"
+)
+
+// Sentence is an element of the chunk.
+type Sentence struct {
+	// Sequence number of the sentence within the source, starting from 0.
+	Index int
+
+	// Byte offsets [Offset, Offset+Length) of the sentence within the source.
+	// Offsets are only defined if the Reader implements Spanner interface.
+	Offset int
+	Length int
+
+	// Text of the sentence
+	Text string
+
+	// Section of the document the sentence belongs to.
+	// Sections are only defined if the Reader implements Sectioner interface.
+	Section Section
+
+	// Embedding vector of the sentence. If the scanner combines sentences
+	// with neighbours (see [Scanner.BufferSize]), it is the vector of
+	// the combined text rather than the sentence alone.
+	Vector []float32
+}
+
+// Section is a structural part of the document (e.g. Markdown heading).
+type Section struct {
+	// Sequence number of the section within the source, starting from 0.
+	Index int
+
+	// Heading path of the section (e.g. H1 > H2 > H3).
+	Path []string
+}
+
+// Chunk is semantically similar group of sentences produced by the Scanner.
+type Chunk struct {
+	// Stable identity of the chunk, it is defined by level of the chunk and
+	// index of its first sentence (e.g. "0:17").
+	ID string
+
+	// Identity of the parent chunk, the parent is only defined for
+	// hierarchical chunking.
+	Parent string
+
+	// Level of the chunk in the hierarchy, 0 is the finest one.
+	Level int
+
+	Sentences []Sentence
+
+	// Section of the document the chunk belongs to.
+	Section Section
+
+	// Metadata of the chunk defined by chunking strategy (e.g. source file
+	// and line range of the code).
+	Meta map[string]string
+
+	// Centroid is the mean vector of sentences, it is nil if vectors have
+	// different dimensions. It is the mean of combined vectors if the scanner
+	// combines sentences with neighbours (see [Scanner.BufferSize]).
+	Centroid []float32
+
+	// Similarity is the average cosine similarity of sentences to the centroid.
+	// It is scaled to [0, 1] so that 1 means identical sentences, i.e. it is
+	// 1 - cosine distance as it is used by similarity functions.
+	Similarity float32
+}
+
+// Chunker is the common interface of chunking strategies. Successive calls
+// to the Scan method step through chunks of the source.
+type Chunker interface {
+	Scan() bool
+	Chunk() Chunk
+	Err() error
+}
+
+// Spanner is an optional interface implemented by Reader.
+// It reports the byte offsets [lo, hi) of the latest sentence in the source.
+type Spanner interface {
+	Span() (int, int)
+}
+
+// Sectioner is an optional interface implemented by Reader.
+// It reports the section of the latest sentence. Sections are hard boundaries,
+// sentences of different sections are never grouped into the same chunk.
+type Sectioner interface {
+	Section() Section
+}
+
+// Text returns text of sentences in the chunk.
+func (c Chunk) Text() []string {
+	if len(c.Sentences) == 0 {
+		return nil
+	}
+
+	seq := make([]string, len(c.Sentences))
+	for i, x := range c.Sentences {
+		seq[i] = x.Text
+	}
+	return seq
+}
+
+// Creates chunk from sentences, calculating centroid and similarity.
+func newChunk(seq []Sentence) Chunk {
+	if len(seq) == 0 {
+		return Chunk{}
+	}
+
+	vecs := make([][]float32, len(seq))
+	for i, x := range seq {
+		vecs[i] = x.Vector
+	}
+
+	// centroid is not defined for vectors of different dimensions
+	centroid, _ := vector.Mean(vecs...)
+
+	return Chunk{
+		ID:         chunkID(0, seq[0].Index),
+		Sentences:  seq,
+		Section:    seq[0].Section,
+		Centroid:   centroid,
+		Similarity: similarity(centroid, seq),
+	}
+}
+
+// stable identity of the chunk
+func chunkID(level, index int) string {
+	return strconv.Itoa(level) + ":" + strconv.Itoa(index)
+}
+
+// average cosine similarity of sentences to the vector, scaled to [0, 1].
+// Vectors of different dimensions are considered to be dissimilar.
+func similarity(v []float32, seq []Sentence) float32 {
+	sum := float32(0.0)
+	for _, x := range seq {
+		if s, err := vector.CosineSimilarity(v, x.Vector); err == nil {
+			sum += (1 + s) / 2
+		}
+	}
+
+	return sum / float32(len(seq))
+}
@@ -0,0 +1,244 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// GoSource provides chunking of Go source code. It emits chunk per top-level
+// declaration (func, method, type, const and var blocks) with doc comment
+// attached. Package clause is emitted if it has doc comment, imports are
+// skipped. Functions larger than the size are split at statement boundaries.
+//
+// The chunk has single sentence, the source code of declaration, embedding
+// vectors are not calculated. The chunk metadata defines:
+//   - "file" the name of source file;
+//   - "kind" of declaration: package, func, method, type, const or var;
+//   - "name" of declaration, methods are prefixed with receiver type;
+//   - "lines" the line range of declaration (e.g. "10-42");
+//   - "part" the part of split function (e.g. "2/3").
+//
+// The identity of chunk is defined by index of the declaration and the part
+// of split function (e.g. "0:7", "0:7.2").
+//
+// The size is measured in characters (runes), use Size method to change
+// default 2000 characters value. The source is parsed entirely on the first
+// call to Scan.
+type GoSource struct {
+	confSize int
+	file     string
+	reader   io.Reader
+	fset     *token.FileSet
+	source   []byte
+	chunks   []Chunk
+	cursor   Chunk
+	err      error
+}
+
+var _ Chunker = (*GoSource)(nil)
+
+// Creates new instance of Go source code chunking, the file name is used
+// for error reporting and chunk metadata.
+func NewGoSource(file string, r io.Reader) *GoSource {
+	return &GoSource{
+		confSize: 2000,
+		file:     file,
+		reader:   r,
+		fset:     token.NewFileSet(),
+	}
+}
+
+// Size defines the size of chunk in characters, larger functions are split.
+// The default value is 2000 characters.
+func (s *GoSource) Size(n int) {
+	s.confSize = max(n, 1)
+}
+
+func (s *GoSource) Err() error     { return s.err }
+func (s *GoSource) Text() []string { return s.cursor.Text() }
+func (s *GoSource) Chunk() Chunk   { return s.cursor }
+
+// Scan advances to the next chunk, which will then be available through
+// [GoSource.Text] and [GoSource.Chunk]. It returns false if there was
+// I/O or syntax error or EOF is reached.
+func (s *GoSource) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	if s.reader != nil {
+		s.source, s.err = io.ReadAll(s.reader)
+		s.reader = nil
+		if s.err != nil {
+			return false
+		}
+
+		if s.err = s.parse(); s.err != nil {
+			return false
+		}
+	}
+
+	if len(s.chunks) == 0 {
+		s.cursor = Chunk{}
+		return false
+	}
+
+	s.cursor = s.chunks[0]
+	s.chunks = s.chunks[1:]
+
+	return true
+}
+
+func (s *GoSource) parse() error {
+	f, err := parser.ParseFile(s.fset, s.file, s.source, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	if f.Doc != nil {
+		s.emit(0, "package", f.Name.Name, f.Doc.Pos(), f.Name.End(), 0, 0)
+	}
+
+	for i, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			s.funcDecl(i+1, d)
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			s.genDecl(i+1, d)
+		}
+	}
+
+	return nil
+}
+
+func (s *GoSource) funcDecl(index int, d *ast.FuncDecl) {
+	kind, name := "func", d.Name.Name
+	if d.Recv != nil && len(d.Recv.List) > 0 {
+		kind, name = "method", receiver(d.Recv.List[0].Type)+"."+name
+	}
+
+	pos := d.Pos()
+	if d.Doc != nil {
+		pos = d.Doc.Pos()
+	}
+
+	if d.Body == nil || len(d.Body.List) < 2 || s.length(pos, d.End()) <= s.confSize {
+		s.emit(index, kind, name, pos, d.End(), 0, 0)
+		return
+	}
+
+	// split the function at statement boundaries, each part is up to size
+	// unless the statement is larger.
+	parts := make([][2]token.Pos, 0)
+	lo, hi := pos, pos
+	for _, stmt := range d.Body.List {
+		if hi != lo && s.length(lo, stmt.End()) > s.confSize {
+			parts = append(parts, [2]token.Pos{lo, hi})
+			lo = s.skipSpace(hi)
+		}
+		hi = stmt.End()
+	}
+	parts = append(parts, [2]token.Pos{lo, d.End()})
+
+	for i, x := range parts {
+		s.emit(index, kind, name, x[0], x[1], i+1, len(parts))
+	}
+}
+
+func (s *GoSource) genDecl(index int, d *ast.GenDecl) {
+	names := make([]string, 0)
+	for _, spec := range d.Specs {
+		switch x := spec.(type) {
+		case *ast.TypeSpec:
+			names = append(names, x.Name.Name)
+		case *ast.ValueSpec:
+			for _, n := range x.Names {
+				names = append(names, n.Name)
+			}
+		}
+	}
+
+	pos := d.Pos()
+	if d.Doc != nil {
+		pos = d.Doc.Pos()
+	}
+
+	s.emit(index, d.Tok.String(), strings.Join(names, ", "), pos, d.End(), 0, 0)
+}
+
+// emits the chunk of declaration, the part of split function is defined
+// if parts > 0
+func (s *GoSource) emit(index int, kind, name string, pos, end token.Pos, part, parts int) {
+	lo, hi := s.fset.PositionFor(pos, false), s.fset.PositionFor(end, false)
+
+	meta := map[string]string{
+		"file":  s.file,
+		"kind":  kind,
+		"name":  name,
+		"lines": strconv.Itoa(lo.Line) + "-" + strconv.Itoa(hi.Line),
+	}
+	id := chunkID(0, index)
+	if parts > 0 {
+		meta["part"] = fmt.Sprintf("%d/%d", part, parts)
+		id += "." + strconv.Itoa(part)
+	}
+
+	s.chunks = append(s.chunks,
+		Chunk{
+			ID: id,
+			Sentences: []Sentence{
+				{
+					Index:  index,
+					Offset: lo.Offset,
+					Length: hi.Offset - lo.Offset,
+					Text:   string(s.source[lo.Offset:hi.Offset]),
+				},
+			},
+			Meta: meta,
+		},
+	)
+}
+
+// length of the source code in characters
+func (s *GoSource) length(pos, end token.Pos) int {
+	lo, hi := s.fset.PositionFor(pos, false), s.fset.PositionFor(end, false)
+	return utf8.RuneCount(s.source[lo.Offset:hi.Offset])
+}
+
+// skip spaces starting from the position
+func (s *GoSource) skipSpace(pos token.Pos) token.Pos {
+	at := s.fset.PositionFor(pos, false).Offset
+	return pos + token.Pos(skipSpace(s.source, at)-at)
+}
+
+// name of receiver type
+func receiver(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return receiver(x.X)
+	case *ast.IndexExpr:
+		return receiver(x.X)
+	case *ast.IndexListExpr:
+		return receiver(x.X)
+	case *ast.Ident:
+		return x.Name
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio/scanner"
+)
+
+const gosource = `// Package demo is an example.
+package demo
+
+import "fmt"
+
+// Answer to everything
+const Answer = 42
+
+var (
+	a = 1
+	b = 2
+)
+
+// T is a type
+type T[A any] struct{}
+
+// Hello says hello
+func Hello() {
+	fmt.Println("hello")
+}
+
+// Say is a method
+func (t *T[A]) Say(n int) {
+	fmt.Println("one")
+	fmt.Println("two")
+	// comment
+	fmt.Println("three")
+}
+`
+
+func TestGoSource(t *testing.T) {
+	t.Run("Declarations", func(t *testing.T) {
+		s := scanner.NewGoSource("demo.go", strings.NewReader(gosource))
+
+		seq := make([]string, 0)
+		for s.Scan() {
+			c := s.Chunk()
+			seq = append(seq, c.ID+" "+c.Meta["kind"]+" "+c.Meta["name"]+" "+c.Meta["lines"])
+
+			x := c.Sentences[0]
+			it.Then(t).Should(
+				it.Equal(c.Meta["file"], "demo.go"),
+				it.Equal(gosource[x.Offset:x.Offset+x.Length], x.Text),
+			)
+		}
+
+		it.Then(t).Should(
+			it.Nil(s.Err()),
+			it.Seq(seq).Equal(
+				"0:0 package demo 1-2",
+				"0:2 const Answer 6-7",
+				"0:3 var a, b 9-12",
+				"0:4 type T 14-15",
+				"0:5 func Hello 17-20",
+				"0:6 method T.Say 22-28",
+			),
+		)
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		s := scanner.NewGoSource("demo.go", strings.NewReader(gosource))
+		s.Size(60)
+
+		seq := make([]string, 0)
+		for s.Scan() {
+			c := s.Chunk()
+			if c.Meta["name"] == "T.Say" {
+				seq = append(seq, c.ID+" "+c.Meta["part"]+" "+c.Meta["lines"]+" "+c.Text()[0])
+			}
+		}
+
+		it.Then(t).Should(
+			it.Seq(seq).Equal(
+				"0:6.1 1/2 22-24 // Say is a method\nfunc (t *T[A]) Say(n int) {\n\tfmt.Println(\"one\")",
+				"0:6.2 2/2 25-28 fmt.Println(\"two\")\n\t// comment\n\tfmt.Println(\"three\")\n}",
+			),
+		)
+	})
+
+	t.Run("LineDirective", func(t *testing.T) {
+		src := "package demo\n\n//line parser.y:100\nfunc A() {}\n\nfunc B() {}\n"
+		s := scanner.NewGoSource("demo.go", strings.NewReader(src))
+
+		seq := make([]string, 0)
+		for s.Scan() {
+			c := s.Chunk()
+			x := c.Sentences[0]
+			seq = append(seq, c.Meta["lines"]+" "+src[x.Offset:x.Offset+x.Length])
+		}
+
+		it.Then(t).Should(
+			it.Nil(s.Err()),
+			it.Seq(seq).Equal("3-4 //line parser.y:100\nfunc A() {}", "6-6 func B() {}"),
+		)
+	})
+
+	t.Run("SyntaxError", func(t *testing.T) {
+		s := scanner.NewGoSource("demo.go", strings.NewReader("package demo\nfunc {"))
+
+		it.Then(t).ShouldNot(
+			it.True(s.Scan()),
+			it.Nil(s.Err()),
+		)
+	})
+}
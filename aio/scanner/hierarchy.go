@@ -0,0 +1,75 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+// parent chunk being formed
+type parent struct {
+	id  string
+	seq []Sentence
+}
+
+// Hierarchy enables hierarchical (parent/child) chunking. Semantic chunks are
+// children (level 0), parents of level i+1 are merged neighbouring chunks of
+// level i up to the size limit in sentences, e.g.
+//
+//	s.Hierarchy(64)      // two levels, parents up to 64 sentences
+//	s.Hierarchy(64, 256) // three levels
+//
+// Parents never span multiple sections. The scanner emits the parent after
+// its last child. Each chunk has stable identity and the link to its parent.
+func (s *Scanner) Hierarchy(sizes ...int) {
+	s.confHierarchy = sizes
+	s.parents = make([]parent, len(sizes))
+}
+
+// lift the chunk to the parent, the parent is emitted if it is full.
+func (s *Scanner) lift(chunk *Chunk) {
+	level := chunk.Level
+	if level >= len(s.confHierarchy) {
+		return
+	}
+
+	p := &s.parents[level]
+	if len(p.seq) > 0 &&
+		(len(p.seq)+len(chunk.Sentences) > s.confHierarchy[level] ||
+			p.seq[0].Section.Index != chunk.Section.Index) {
+		s.close(level)
+	}
+
+	if len(p.seq) == 0 {
+		p.id = chunkID(level+1, chunk.Sentences[0].Index)
+	}
+
+	p.seq = append(p.seq, chunk.Sentences...)
+	chunk.Parent = p.id
+}
+
+// close the parent at the level
+func (s *Scanner) close(level int) {
+	p := &s.parents[level]
+	if len(p.seq) == 0 {
+		return
+	}
+
+	chunk := newChunk(p.seq)
+	chunk.ID = p.id
+	chunk.Level = level + 1
+
+	p.id, p.seq = "", nil
+
+	s.lift(&chunk)
+	s.queue = append(s.queue, chunk)
+}
+
+// flush all parents
+func (s *Scanner) flush() {
+	for level := range s.parents {
+		s.close(level)
+	}
+}
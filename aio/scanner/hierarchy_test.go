@@ -0,0 +1,76 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio/scanner"
+)
+
+func TestHierarchy(t *testing.T) {
+	text := "a. bb. c. ddd. ff."
+
+	type chunk struct {
+		id, parent string
+		level      int
+		text       string
+	}
+
+	scan := func(sizes ...int) []chunk {
+		s := scanner.New(embed{}, scanner.NewSentences(strings.NewReader(text)))
+		s.Similarity(similar)
+		s.Window(3)
+		s.Hierarchy(sizes...)
+
+		seq := make([]chunk, 0)
+		for s.Scan() {
+			c := s.Chunk()
+			seq = append(seq, chunk{c.ID, c.Parent, c.Level, strings.Join(c.Text(), " ")})
+		}
+		return seq
+	}
+
+	t.Run("TwoLevels", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Seq(scan(3)).Equal(
+				chunk{"0:0", "1:0", 0, "a. c."},
+				chunk{"1:0", "", 1, "a. c."},
+				chunk{"0:1", "1:1", 0, "bb. ff."},
+				chunk{"0:3", "1:1", 0, "ddd."},
+				chunk{"1:1", "", 1, "bb. ff. ddd."},
+			),
+		)
+	})
+
+	t.Run("ThreeLevels", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Seq(scan(3, 8)).Equal(
+				chunk{"0:0", "1:0", 0, "a. c."},
+				chunk{"1:0", "2:0", 1, "a. c."},
+				chunk{"0:1", "1:1", 0, "bb. ff."},
+				chunk{"0:3", "1:1", 0, "ddd."},
+				chunk{"1:1", "2:0", 1, "bb. ff. ddd."},
+				chunk{"2:0", "", 2, "a. c. bb. ff. ddd."},
+			),
+		)
+	})
+
+	t.Run("Flat", func(t *testing.T) {
+		it.Then(t).Should(
+			it.Seq(scan()).Equal(
+				chunk{"0:0", "", 0, "a. c."},
+				chunk{"0:1", "", 0, "bb. ff."},
+				chunk{"0:3", "", 0, "ddd."},
+			),
+		)
+	})
+}
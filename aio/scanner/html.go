@@ -0,0 +1,266 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// HTML is a Reader of HTML documents. It emits sentences of the document
+// text, entities are decoded. The content of script, style, navigation and
+// other boilerplate elements is dropped. Block-level elements (paragraphs,
+// list items, table cells, etc) are boundaries, sentences never span them.
+// Preformatted text is emitted as atomic unit. Each heading starts a new
+// section, the heading path (H1 > H2 > H3) is attached to units through
+// [HTML.Section]. Headings are not emitted as units, the heading ends at
+// the next block-level element if it is not closed.
+//
+// Text of block-level elements is split into sentences using split function,
+// the default one is [ScanSentence]. Use Split method to change it.
+type HTML struct {
+	z       *html.Tokenizer
+	split   bufio.SplitFunc
+	queue   []unit
+	unit    unit
+	outline outline
+	skip    int
+	pre     int
+	heading int
+	title   strings.Builder
+	text    strings.Builder
+	eof     bool
+	err     error
+}
+
+var (
+	_ Reader    = (*HTML)(nil)
+	_ Sectioner = (*HTML)(nil)
+)
+
+// elements which content is dropped
+var htmlBoilerplate = map[string]struct{}{
+	"script": {}, "style": {}, "noscript": {}, "template": {}, "svg": {},
+	"nav": {}, "footer": {}, "aside": {}, "button": {},
+	"iframe": {}, "object": {}, "canvas": {}, "title": {},
+}
+
+// block-level elements
+var htmlBlock = map[string]struct{}{
+	"address": {}, "article": {}, "blockquote": {}, "body": {}, "br": {},
+	"caption": {}, "dd": {}, "details": {}, "dialog": {}, "div": {}, "dl": {},
+	"dt": {}, "fieldset": {}, "figcaption": {}, "figure": {}, "header": {},
+	"hgroup": {}, "hr": {}, "li": {}, "main": {}, "ol": {}, "p": {},
+	"section": {}, "summary": {}, "table": {}, "tbody": {}, "td": {},
+	"tfoot": {}, "th": {}, "thead": {}, "tr": {}, "ul": {},
+}
+
+// Creates new instance of HTML reader.
+func NewHTML(r io.Reader) *HTML {
+	return &HTML{
+		z:       html.NewTokenizer(r),
+		split:   ScanSentence,
+		queue:   make([]unit, 0),
+		outline: newOutline(),
+	}
+}
+
+// Split sets the split function used to break text into sentences.
+func (h *HTML) Split(split bufio.SplitFunc) {
+	h.split = split
+}
+
+func (h *HTML) Err() error       { return h.err }
+func (h *HTML) Text() string     { return h.unit.text }
+func (h *HTML) Section() Section { return h.unit.section }
+
+// Scan advances the reader to the next unit, which will then be available
+// through the Text method. It returns false when the scan stops, either by
+// reaching the end of the input or an error.
+func (h *HTML) Scan() bool {
+	for len(h.queue) == 0 {
+		if h.err != nil || h.eof {
+			return false
+		}
+		h.token()
+	}
+
+	h.unit = h.queue[0]
+	h.queue = h.queue[1:]
+	return true
+}
+
+// read next token of the document
+func (h *HTML) token() {
+	switch h.z.Next() {
+	case html.ErrorToken:
+		if err := h.z.Err(); err != io.EOF {
+			h.err = err
+			return
+		}
+		h.flush()
+		h.eof = true
+
+	case html.StartTagToken:
+		name, _ := h.z.TagName()
+		h.open(string(name))
+
+	case html.SelfClosingTagToken:
+		name, _ := h.z.TagName()
+		if h.skip == 0 {
+			h.boundary(string(name))
+		}
+
+	case html.EndTagToken:
+		name, _ := h.z.TagName()
+		h.close(string(name))
+
+	case html.TextToken:
+		if h.skip > 0 {
+			return
+		}
+
+		if h.heading > 0 {
+			h.title.Write(h.z.Text())
+		} else {
+			h.text.Write(h.z.Text())
+		}
+	}
+}
+
+func (h *HTML) open(name string) {
+	if _, has := htmlBoilerplate[name]; has {
+		h.skip++
+		return
+	}
+
+	if h.skip > 0 {
+		return
+	}
+
+	if h.heading > 0 && name != "br" && (htmlHeading(name) > 0 || name == "pre" || htmlIsBlock(name)) {
+		h.enter()
+	}
+
+	if level := htmlHeading(name); level > 0 {
+		h.flush()
+		h.heading = level
+		h.title.Reset()
+		return
+	}
+
+	if name == "pre" {
+		h.flush()
+		h.pre++
+		return
+	}
+
+	h.boundary(name)
+}
+
+func (h *HTML) close(name string) {
+	if _, has := htmlBoilerplate[name]; has {
+		if h.skip > 0 {
+			h.skip--
+		}
+		return
+	}
+
+	if h.skip > 0 {
+		return
+	}
+
+	if h.heading > 0 && (htmlHeading(name) > 0 || (name != "br" && htmlIsBlock(name))) {
+		h.enter()
+	}
+
+	if htmlHeading(name) > 0 {
+		return
+	}
+
+	if name == "pre" && h.pre > 0 {
+		h.pre--
+		if h.pre == 0 {
+			text := strings.Trim(h.text.String(), "\n")
+			h.text.Reset()
+			if strings.TrimSpace(text) != "" {
+				h.emit(text)
+			}
+		}
+		return
+	}
+
+	h.boundary(name)
+}
+
+// enters the section of the heading
+func (h *HTML) enter() {
+	h.outline.enter(h.heading, strings.Join(strings.Fields(h.title.String()), " "))
+	h.heading = 0
+}
+
+// block-level elements flushes the text
+func (h *HTML) boundary(name string) {
+	if !htmlIsBlock(name) {
+		return
+	}
+
+	if h.pre > 0 {
+		if name == "br" {
+			h.text.WriteString("\n")
+		}
+		return
+	}
+
+	h.flush()
+}
+
+// split the accumulated text into sentences
+func (h *HTML) flush() {
+	if h.pre > 0 {
+		return
+	}
+
+	text := strings.Join(strings.Fields(h.text.String()), " ")
+	h.text.Reset()
+	if text == "" {
+		return
+	}
+
+	s := NewSentences(strings.NewReader(text))
+	s.Split(h.split)
+	for s.Scan() {
+		if len(s.Text()) != 0 {
+			h.emit(s.Text())
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		h.err = err
+	}
+}
+
+func (h *HTML) emit(text string) {
+	h.queue = append(h.queue, unit{text: text, section: h.outline.current()})
+}
+
+func htmlIsBlock(name string) bool {
+	_, has := htmlBlock[name]
+	return has
+}
+
+func htmlHeading(name string) int {
+	if len(name) == 2 && name[0] == 'h' && '1' <= name[1] && name[1] <= '6' {
+		return int(name[1] - '0')
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio/scanner"
+)
+
+const htmldoc = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Wiki</title>
+  <style>p { color: red; }</style>
+  <script>var a = "b. c.";</script>
+</head>
+<body>
+  <nav><a href="/">Home</a> <a href="/about">About</a></nav>
+  <p>Intro &amp; overview. Second
+     sentence</p>
+  <h1>Guide</h1>
+  <p>Tom&#39;s <b>bold</b> text. Next one.</p>
+  <h2>List</h2>
+  <ul>
+    <li>First item. Still first.</li>
+    <li>Second item</li>
+  </ul>
+  <pre>func main() {
+	fmt.Println("a. b.")
+}</pre>
+  <table><tr><td>Cell a</td><td>Cell b</td></tr></table>
+  <h1>Next</h1>
+  <div>Done<br>Really done.</div>
+  <footer>Copyright.</footer>
+</body>
+</html>`
+
+func TestHTML(t *testing.T) {
+	r := scanner.NewHTML(strings.NewReader(htmldoc))
+
+	type unit struct {
+		text string
+		path string
+		sect int
+	}
+
+	seq := make([]unit, 0)
+	for r.Scan() {
+		sec := r.Section()
+		seq = append(seq, unit{text: r.Text(), path: strings.Join(sec.Path, " > "), sect: sec.Index})
+	}
+
+	it.Then(t).Should(
+		it.Nil(r.Err()),
+		it.Seq(seq).Equal(
+			unit{"Intro & overview.", "", 0},
+			unit{"Second sentence", "", 0},
+			unit{"Tom's bold text.", "Guide", 1},
+			unit{"Next one.", "Guide", 1},
+			unit{"First item.", "Guide > List", 2},
+			unit{"Still first.", "Guide > List", 2},
+			unit{"Second item", "Guide > List", 2},
+			unit{"func main() {\n\tfmt.Println(\"a. b.\")\n}", "Guide > List", 2},
+			unit{"Cell a", "Guide > List", 2},
+			unit{"Cell b", "Guide > List", 2},
+			unit{"Done", "Next", 3},
+			unit{"Really done.", "Next", 3},
+		),
+	)
+}
+
+func TestHTMLForm(t *testing.T) {
+	doc := `<body><form id="aspnetForm"><h1>Title</h1><p>Hello. World.</p><button>Send</button></form></body>`
+	r := scanner.NewHTML(strings.NewReader(doc))
+
+	seq := make([]string, 0)
+	for r.Scan() {
+		seq = append(seq, r.Text())
+	}
+
+	it.Then(t).Should(
+		it.Nil(r.Err()),
+		it.Seq(seq).Equal("Hello.", "World."),
+	)
+}
+
+func TestHTMLUnclosedHeading(t *testing.T) {
+	doc := `<h2>Title <b>bold</b><p>Text of section.</p><div>More text.</div>`
+	r := scanner.NewHTML(strings.NewReader(doc))
+
+	seq := make([]string, 0)
+	path := make([]string, 0)
+	for r.Scan() {
+		seq = append(seq, r.Text())
+		path = append(path, strings.Join(r.Section().Path, " > "))
+	}
+
+	it.Then(t).Should(
+		it.Nil(r.Err()),
+		it.Seq(seq).Equal("Text of section.", "More text."),
+		it.Seq(path).Equal("Title bold", "Title bold"),
+	)
+}
@@ -0,0 +1,79 @@
+//
+// Copyright (C) 2024 - 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+import "github.com/kshard/embeddings/vector"
+
+// Configure similarity sorting algorithm
+type SimilarityWith int
+
+// Configure similarity sorting algorithm
+const (
+	SIMILARITY_WITH_HEAD SimilarityWith = iota
+	SIMILARITY_WITH_TAIL
+	SIMILARITY_WITH_CENTROID
+	SIMILARITY_WITH_ALL
+)
+
+// split the window into similar (a) and non-similar (b) items, the first
+// element of the window always belongs to similar items.
+func split[T any](
+	window []T,
+	vec func(T) []float32,
+	with SimilarityWith,
+	similar func([]float32, []float32) bool,
+) (a []T, b []T) {
+	a, b = make([]T, 0), make([]T, 0)
+	if len(window) == 0 {
+		return
+	}
+
+	a = append(a, window[0])
+
+	// running sum of vectors in a, used by centroid
+	var sum []float32
+	if with == SIMILARITY_WITH_CENTROID {
+		sum = append(sum, vec(window[0])...)
+	}
+
+	for i := 1; i < len(window); i++ {
+		v := vec(window[i])
+
+		var has bool
+		switch with {
+		case SIMILARITY_WITH_HEAD:
+			has = similar(vec(a[0]), v)
+		case SIMILARITY_WITH_TAIL:
+			has = similar(vec(a[len(a)-1]), v)
+		case SIMILARITY_WITH_CENTROID:
+			has = similar(vector.Scale(sum, 1/float32(len(a))), v)
+		case SIMILARITY_WITH_ALL:
+			has = true
+			for _, x := range a {
+				if !similar(vec(x), v) {
+					has = false
+					break
+				}
+			}
+		}
+
+		if has && with == SIMILARITY_WITH_CENTROID {
+			// vectors of different dimensions do not belong to the centroid
+			has = vector.Add(sum, v) == nil
+		}
+
+		if has {
+			a = append(a, window[i])
+		} else {
+			b = append(b, window[i])
+		}
+	}
+
+	return
+}
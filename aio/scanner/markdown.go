@@ -0,0 +1,339 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Markdown is a Reader of Markdown documents. It emits prose sentences,
+// fenced code blocks and tables are emitted as atomic units. Each heading
+// starts a new section, the heading path (H1 > H2 > H3) is attached to units
+// through [Markdown.Section]. Headings are not emitted as units.
+//
+// Paragraphs, list items and block quotes are split into sentences using
+// split function, the default one is [ScanSentence]. Use Split method to
+// change it.
+type Markdown struct {
+	lines   *Sentences
+	split   bufio.SplitFunc
+	peeked  []line
+	queue   []unit
+	unit    unit
+	outline outline
+	err     error
+}
+
+var (
+	_ Reader    = (*Markdown)(nil)
+	_ Spanner   = (*Markdown)(nil)
+	_ Sectioner = (*Markdown)(nil)
+)
+
+// line of the source
+type line struct {
+	text   string
+	lo, hi int
+}
+
+// unit emitted by the reader
+type unit struct {
+	text    string
+	lo, hi  int
+	section Section
+}
+
+// segment of the paragraph, text at source offset
+type segment struct {
+	text string
+	at   int
+}
+
+var (
+	reHeading   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	reSetext    = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	reFence     = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+	reDelimiter = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	reListItem  = regexp.MustCompile(`^[ \t]*([-*+]|\d{1,9}[.)])[ \t]+`)
+	reQuote     = regexp.MustCompile(`^ {0,3}>[ \t]?`)
+	reBreak     = regexp.MustCompile(`^ {0,3}((\*[ \t]*){3,}|(-[ \t]*){3,}|(_[ \t]*){3,})$`)
+)
+
+// Creates new instance of Markdown reader.
+func NewMarkdown(r io.Reader) *Markdown {
+	lines := NewSentenceSpans(r)
+	lines.Split(bufio.ScanLines)
+
+	return &Markdown{
+		lines:   lines,
+		split:   ScanSentence,
+		peeked:  make([]line, 0),
+		queue:   make([]unit, 0),
+		outline: newOutline(),
+	}
+}
+
+// Split sets the split function used to break prose into sentences.
+func (m *Markdown) Split(split bufio.SplitFunc) {
+	m.split = split
+}
+
+func (m *Markdown) Err() error       { return m.err }
+func (m *Markdown) Text() string     { return m.unit.text }
+func (m *Markdown) Span() (int, int) { return m.unit.lo, m.unit.hi }
+func (m *Markdown) Section() Section { return m.unit.section }
+
+// Scan advances the reader to the next unit, which will then be available
+// through the Text method. It returns false when the scan stops, either by
+// reaching the end of the input or an error.
+func (m *Markdown) Scan() bool {
+	for len(m.queue) == 0 {
+		if m.err != nil || !m.block() {
+			return false
+		}
+	}
+
+	m.unit = m.queue[0]
+	m.queue = m.queue[1:]
+	return true
+}
+
+// read next line of the source
+func (m *Markdown) next() (line, bool) {
+	if n := len(m.peeked); n > 0 {
+		l := m.peeked[n-1]
+		m.peeked = m.peeked[:n-1]
+		return l, true
+	}
+
+	if !m.lines.Scan() {
+		m.err = m.lines.Err()
+		return line{}, false
+	}
+
+	lo, hi := m.lines.Span()
+	return line{text: m.lines.Text(), lo: lo, hi: hi}, true
+}
+
+// peek next line of the source
+func (m *Markdown) peek() (line, bool) {
+	l, ok := m.next()
+	if ok {
+		m.unread(l)
+	}
+	return l, ok
+}
+
+// return the line back to the source
+func (m *Markdown) unread(l line) {
+	m.peeked = append(m.peeked, l)
+}
+
+// read next block of the source into queue
+func (m *Markdown) block() bool {
+	for {
+		l, ok := m.next()
+		if !ok {
+			return false
+		}
+
+		switch {
+		case strings.TrimSpace(l.text) == "":
+			continue
+		case reHeading.MatchString(l.text):
+			m.heading(l)
+			continue
+		case reBreak.MatchString(l.text):
+			continue
+		case reFence.MatchString(l.text):
+			m.fence(l)
+			return true
+		case m.isTable(l):
+			m.table(l)
+			return true
+		default:
+			m.paragraph(l)
+			return true
+		}
+	}
+}
+
+// the heading starts new section
+func (m *Markdown) heading(l line) {
+	match := reHeading.FindStringSubmatch(l.text)
+	m.outline.enter(len(match[1]), strings.TrimSpace(match[2]))
+}
+
+// fenced code block is atomic unit
+func (m *Markdown) fence(first line) {
+	marker := reFence.FindStringSubmatch(first.text)[1]
+	seq := []string{first.text}
+	last := first
+
+	for {
+		l, ok := m.next()
+		if !ok {
+			break
+		}
+
+		seq = append(seq, l.text)
+		last = l
+
+		text := strings.TrimSpace(l.text)
+		if strings.HasPrefix(text, marker) && strings.Trim(text, marker[:1]) == "" {
+			break
+		}
+	}
+
+	m.emit(strings.Join(seq, "\n"), first.lo, last.hi)
+}
+
+// table is the header row followed by delimiter row
+func (m *Markdown) isTable(l line) bool {
+	if !strings.Contains(l.text, "|") {
+		return false
+	}
+
+	next, ok := m.peek()
+	return ok && strings.Contains(next.text, "-") && reDelimiter.MatchString(next.text)
+}
+
+// table is atomic unit
+func (m *Markdown) table(first line) {
+	seq := []string{first.text}
+	last := first
+
+	for {
+		l, ok := m.next()
+		if !ok {
+			break
+		}
+
+		if strings.TrimSpace(l.text) == "" || !strings.Contains(l.text, "|") {
+			m.unread(l)
+			break
+		}
+
+		seq = append(seq, l.text)
+		last = l
+	}
+
+	m.emit(strings.Join(seq, "\n"), first.lo, last.hi)
+}
+
+// paragraph, list item or block quote is split into sentences
+func (m *Markdown) paragraph(first line) {
+	item := reListItem.MatchString(first.text)
+	seq := []segment{m.content(first)}
+
+	for {
+		l, ok := m.next()
+		if !ok {
+			break
+		}
+
+		// setext heading underlines the paragraph
+		if !item && reSetext.MatchString(l.text) {
+			level := 1
+			if strings.Contains(l.text, "-") {
+				level = 2
+			}
+
+			title := make([]string, len(seq))
+			for i, x := range seq {
+				title[i] = x.text
+			}
+			m.outline.enter(level, strings.Join(title, " "))
+			return
+		}
+
+		if strings.TrimSpace(l.text) == "" ||
+			reHeading.MatchString(l.text) ||
+			reFence.MatchString(l.text) ||
+			reBreak.MatchString(l.text) ||
+			reListItem.MatchString(l.text) ||
+			m.isTable(l) {
+			m.unread(l)
+			break
+		}
+
+		seq = append(seq, m.content(l))
+	}
+
+	m.sentences(seq)
+}
+
+// content of the line, list and quote markers are removed
+func (m *Markdown) content(l line) segment {
+	at := 0
+	for {
+		if loc := reQuote.FindStringIndex(l.text[at:]); loc != nil {
+			at += loc[1]
+			continue
+		}
+		if loc := reListItem.FindStringIndex(l.text[at:]); loc != nil {
+			at += loc[1]
+			continue
+		}
+		break
+	}
+
+	text := l.text[at:]
+	trimmed := strings.TrimLeft(text, " \t")
+	at += len(text) - len(trimmed)
+
+	return segment{text: strings.TrimRight(trimmed, " \t"), at: l.lo + at}
+}
+
+// split segments into sentences, segments are joined with space
+func (m *Markdown) sentences(seq []segment) {
+	var buf bytes.Buffer
+	pos := make([]int, len(seq))
+	for i, x := range seq {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		pos[i] = buf.Len()
+		buf.WriteString(x.text)
+	}
+
+	// maps offset within paragraph to the source
+	source := func(p int) int {
+		i := len(pos) - 1
+		for i > 0 && pos[i] > p {
+			i--
+		}
+		return seq[i].at + min(p-pos[i], len(seq[i].text))
+	}
+
+	s := NewSentenceSpans(&buf)
+	s.Split(m.split)
+	for s.Scan() {
+		if len(s.Text()) == 0 {
+			continue
+		}
+
+		lo, hi := s.Span()
+		m.emit(s.Text(), source(lo), source(hi-1)+1)
+	}
+
+	if err := s.Err(); err != nil {
+		m.err = err
+	}
+}
+
+func (m *Markdown) emit(text string, lo, hi int) {
+	m.queue = append(m.queue,
+		unit{text: text, lo: lo, hi: hi, section: m.outline.current()},
+	)
+}
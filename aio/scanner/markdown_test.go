@@ -0,0 +1,123 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio/scanner"
+)
+
+const markdown = `Intro text. Before heading.
+
+# Guide
+
+The guide starts. It has
+two lines.
+
+## Install
+
+- First item. Still first.
+- Second item
+
+` + "```go" + `
+func main() {
+	fmt.Println("a. b. c.")
+}
+` + "```" + `
+
+| Key | Value |
+|-----|-------|
+| a.  | b.    |
+
+Setext
+------
+
+> Quoted text. Another one.
+
+# Next
+
+Done.`
+
+func TestMarkdown(t *testing.T) {
+	r := scanner.NewMarkdown(strings.NewReader(markdown))
+
+	type unit struct {
+		text string
+		path string
+		sect int
+	}
+
+	seq := make([]unit, 0)
+	for r.Scan() {
+		lo, hi := r.Span()
+		sec := r.Section()
+		seq = append(seq, unit{text: r.Text(), path: strings.Join(sec.Path, " > "), sect: sec.Index})
+
+		if !strings.Contains(r.Text(), "\n") && !strings.Contains(markdown[lo:hi], "\n") {
+			it.Then(t).Should(it.Equal(markdown[lo:hi], r.Text()))
+		}
+	}
+
+	it.Then(t).Should(
+		it.Nil(r.Err()),
+		it.Seq(seq).Equal(
+			unit{"Intro text.", "", 0},
+			unit{"Before heading.", "", 0},
+			unit{"The guide starts.", "Guide", 1},
+			unit{"It has two lines.", "Guide", 1},
+			unit{"First item.", "Guide > Install", 2},
+			unit{"Still first.", "Guide > Install", 2},
+			unit{"Second item", "Guide > Install", 2},
+			unit{"```go\nfunc main() {\n\tfmt.Println(\"a. b. c.\")\n}\n```", "Guide > Install", 2},
+			unit{"| Key | Value |\n|-----|-------|\n| a.  | b.    |", "Guide > Install", 2},
+			unit{"Quoted text.", "Guide > Setext", 3},
+			unit{"Another one.", "Guide > Setext", 3},
+			unit{"Done.", "Next", 4},
+		),
+	)
+}
+
+func TestMarkdownSpan(t *testing.T) {
+	text := "# Title\n\nThe guide starts. It has\ntwo lines."
+	r := scanner.NewMarkdown(strings.NewReader(text))
+
+	seq := make([]string, 0)
+	for r.Scan() {
+		lo, hi := r.Span()
+		seq = append(seq, text[lo:hi])
+	}
+
+	it.Then(t).Should(
+		it.Seq(seq).Equal("The guide starts.", "It has\ntwo lines."),
+	)
+}
+
+func TestScannerMarkdown(t *testing.T) {
+	text := "# A\n\nbb. c.\n\n# B\n\nbb. ddd."
+
+	s := scanner.New(embed{}, scanner.NewMarkdown(strings.NewReader(text)))
+	s.Similarity(func(a, b []float32) bool { return true })
+	s.Window(8)
+
+	it.Then(t).Should(
+		it.True(s.Scan()),
+		it.Seq(s.Text()).Equal("bb.", "c."),
+		it.Seq(s.Chunk().Section.Path).Equal("A"),
+		it.True(s.Scan()),
+		it.Seq(s.Text()).Equal("bb.", "ddd."),
+		it.Seq(s.Chunk().Section.Path).Equal("B"),
+	)
+
+	it.Then(t).ShouldNot(
+		it.True(s.Scan()),
+	)
+}
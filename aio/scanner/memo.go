@@ -0,0 +1,43 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+// memo is bounded memory of embeddings, the oldest entry is evicted first.
+type memo struct {
+	cap  int
+	keys []string
+	vals map[string][]float32
+}
+
+func newMemo(n int) *memo {
+	return &memo{
+		cap:  max(n, 1),
+		keys: make([]string, 0),
+		vals: make(map[string][]float32),
+	}
+}
+
+func (m *memo) get(key string) ([]float32, bool) {
+	v, has := m.vals[key]
+	return v, has
+}
+
+func (m *memo) put(key string, val []float32) {
+	if _, has := m.vals[key]; has {
+		return
+	}
+
+	if len(m.keys) >= m.cap {
+		delete(m.vals, m.keys[0])
+		m.keys = m.keys[1:]
+	}
+
+	m.keys = append(m.keys, key)
+	m.vals[key] = val
+}
@@ -0,0 +1,40 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+// outline of the document, keeps track of heading path
+type outline struct {
+	path    []string
+	levels  []int
+	section int
+}
+
+func newOutline() outline {
+	return outline{
+		path:   make([]string, 0),
+		levels: make([]int, 0),
+	}
+}
+
+// heading of the level starts new section
+func (o *outline) enter(level int, title string) {
+	for len(o.levels) > 0 && o.levels[len(o.levels)-1] >= level {
+		o.levels = o.levels[:len(o.levels)-1]
+		o.path = o.path[:len(o.path)-1]
+	}
+
+	o.levels = append(o.levels, level)
+	o.path = append(o.path, title)
+	o.section++
+}
+
+// current section of the document
+func (o *outline) current() Section {
+	return Section{Index: o.section, Path: append([]string{}, o.path...)}
+}
@@ -0,0 +1,112 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kshard/embeddings"
+)
+
+// prefetch calculates embeddings of texts, preserving the order of texts.
+// It uses batch api if embedder implements [embeddings.BatchEmbedder],
+// otherwise texts are embedded concurrently by bounded number of workers.
+// It fails on the first error, pending texts are not embedded.
+func prefetch(ctx context.Context, embed embeddings.Embedder, workers int, txt []string) ([][]float32, error) {
+	if len(txt) == 0 {
+		return nil, nil
+	}
+
+	if batch, ok := embed.(embeddings.BatchEmbedder); ok {
+		seq, err := batch.Embeddings(ctx, txt)
+		if err != nil {
+			return nil, fmt.Errorf("embedding has failed: %w", err)
+		}
+		if len(seq) != len(txt) {
+			return nil, fmt.Errorf("embedding has failed: expected %d vectors, got %d", len(txt), len(seq))
+		}
+
+		vec := make([][]float32, len(seq))
+		for i, x := range seq {
+			vec[i] = x.Vector
+		}
+		return vec, nil
+	}
+
+	workers = min(max(workers, 1), len(txt))
+	if workers == 1 {
+		vec := make([][]float32, len(txt))
+		for i, x := range txt {
+			v32, err := embed.Embedding(ctx, x)
+			if err != nil {
+				return nil, fmt.Errorf("embedding has failed: %w, for {%s}", err, x)
+			}
+			vec[i] = v32.Vector
+		}
+		return vec, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg   sync.WaitGroup
+		once sync.Once
+		err  error
+	)
+
+	vec := make([][]float32, len(txt))
+	queue := make(chan int)
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				// queued texts are drained without embedding after failure
+				if ctx.Err() != nil {
+					continue
+				}
+
+				v32, fail := embed.Embedding(ctx, txt[i])
+				if fail != nil {
+					once.Do(func() {
+						err = fmt.Errorf("embedding has failed: %w, for {%s}", fail, txt[i])
+						cancel()
+					})
+					continue
+				}
+				vec[i] = v32.Vector
+			}
+		}()
+	}
+
+emit:
+	for i := range txt {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			break emit
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return vec, nil
+}
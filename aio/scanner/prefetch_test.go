@@ -0,0 +1,148 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fogfish/golem/optics"
+	"github.com/fogfish/golem/trait/seq"
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings"
+	"github.com/kshard/embeddings/aio"
+	"github.com/kshard/embeddings/aio/scanner"
+)
+
+func TestPrefetch(t *testing.T) {
+	text := "a. bb. c. ddd. ff."
+
+	t.Run("Workers", func(t *testing.T) {
+		s := scanner.New(embed{}, scanner.NewSentences(strings.NewReader(text)))
+		s.Similarity(similar)
+		s.Window(3)
+		s.Workers(4)
+
+		it.Then(t).Should(
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("a.", "c."),
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("bb.", "ff."),
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("ddd."),
+		)
+
+		it.Then(t).ShouldNot(
+			it.True(s.Scan()),
+		)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		e := &batch{}
+		s := scanner.NewSorter(e,
+			optics.ForProduct1[obj, string](),
+			seq.FromSlice([]obj{{"a."}, {"bb."}, {"c."}, {"ddd."}, {"ff."}}),
+		)
+		s.Similarity(similar)
+		s.Window(3)
+
+		it.Then(t).Should(
+			it.True(s.Next()),
+			it.Seq(s.Value()).Equal(obj{"a."}, obj{"c."}),
+			it.True(s.Next()),
+			it.Seq(s.Value()).Equal(obj{"bb."}, obj{"ff."}),
+			it.True(s.Next()),
+			it.Seq(s.Value()).Equal(obj{"ddd."}),
+			it.Equal(e.calls, 2),
+		)
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		e := &parallel{called: make(chan struct{}, 3), release: make(chan struct{})}
+		s := scanner.New(aio.NewCache(aio.NewMemory(10), e), scanner.NewSentences(strings.NewReader(text)))
+		s.Similarity(similar)
+		s.Window(3)
+		s.Workers(3)
+
+		scanned := make(chan bool)
+		go func() { scanned <- s.Scan() }()
+
+		// all workers are embedding concurrently
+		for range 3 {
+			select {
+			case <-e.called:
+			case <-time.After(5 * time.Second):
+				t.Fatal("embeddings are not concurrent")
+			}
+		}
+		close(e.release)
+
+		it.Then(t).Should(
+			it.True(<-scanned),
+			it.Seq(s.Text()).Equal("a.", "c."),
+		)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		e := &failure{}
+		s := scanner.New(e, scanner.NewSentences(strings.NewReader(text)))
+		s.Window(32)
+		s.Workers(2)
+
+		it.Then(t).ShouldNot(
+			it.True(s.Scan()),
+			it.Nil(s.Err()),
+		)
+
+		// failed call and at most one in-flight call per worker
+		it.Then(t).Should(
+			it.True(e.calls.Load() <= 3),
+		)
+	})
+}
+
+//------------------------------------------------------------------------------
+
+type batch struct{ calls int }
+
+func (*batch) UsedTokens() int { return 0 }
+func (*batch) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	return embeddings.Embedding{}, fmt.Errorf("not supported")
+}
+func (b *batch) Embeddings(ctx context.Context, text []string) ([]embeddings.Embedding, error) {
+	b.calls++
+	seq := make([]embeddings.Embedding, len(text))
+	for i, x := range text {
+		seq[i], _ = embed{}.Embedding(ctx, x)
+	}
+	return seq, nil
+}
+
+type parallel struct{ called, release chan struct{} }
+
+func (*parallel) UsedTokens() int { return 0 }
+func (p *parallel) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	p.called <- struct{}{}
+	<-p.release
+	return embed{}.Embedding(ctx, text)
+}
+
+type failure struct{ calls atomic.Int32 }
+
+func (*failure) UsedTokens() int { return 0 }
+func (f *failure) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	if f.calls.Add(1) == 2 {
+		return embeddings.Embedding{}, fmt.Errorf("failed")
+	}
+	return embed{}.Embedding(ctx, text)
+}
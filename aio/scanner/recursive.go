@@ -0,0 +1,236 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Recursive provides the classic structural chunking. The text is recursively
+// split by separators (paragraph → line → sentence → word → character) until
+// each piece fits the target size, then neighbouring pieces are merged into
+// chunks up to the target size with overlap.
+//
+// The chunk is available as [Chunk] through [Recursive.Chunk], same as for
+// semantic [Scanner]. The pieces of text are chunk's sentences, embedding
+// vectors are not calculated. The target size and overlap are measured in
+// characters (runes), use Size and Overlap methods to change default 1000 and
+// 200 characters values.
+//
+// The source is read entirely on the first call to Scan.
+type Recursive struct {
+	confSize       int
+	confOverlap    int
+	confSeparators []bufio.SplitFunc
+	reader         io.Reader
+	source         string
+	err            error
+	pieces         []span
+	chunks         [][]int
+	cursor         Chunk
+}
+
+var _ Chunker = (*Recursive)(nil)
+
+// span of text [lo, hi) within the source
+type span struct{ lo, hi int }
+
+// Creates new instance of recursive chunking to read from io.Reader.
+func NewRecursive(r io.Reader) *Recursive {
+	return &Recursive{
+		confSize:    1000,
+		confOverlap: 200,
+		confSeparators: []bufio.SplitFunc{
+			ScanParagraph,
+			bufio.ScanLines,
+			ScanSentence,
+			bufio.ScanWords,
+			bufio.ScanRunes,
+		},
+		reader: r,
+	}
+}
+
+// Size defines the target size of chunk in characters.
+// The default value is 1000 characters.
+func (s *Recursive) Size(n int) {
+	s.confSize = max(n, 1)
+}
+
+// Overlap defines number of characters shared by neighbouring chunks.
+// The default value is 200 characters.
+func (s *Recursive) Overlap(n int) {
+	s.confOverlap = max(n, 0)
+}
+
+// Separators defines the hierarchy of split functions, from the coarse to
+// the fine one. The default is paragraph, line, sentence, word, character.
+func (s *Recursive) Separators(split ...bufio.SplitFunc) {
+	s.confSeparators = split
+}
+
+func (s *Recursive) Err() error     { return s.err }
+func (s *Recursive) Text() []string { return s.cursor.Text() }
+func (s *Recursive) Chunk() Chunk   { return s.cursor }
+
+// Scan advances to the next chunk, which will then be available through
+// [Recursive.Text] and [Recursive.Chunk]. It returns false if there was
+// I/O error or EOF is reached.
+func (s *Recursive) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	if s.reader != nil {
+		buf, err := io.ReadAll(s.reader)
+		s.reader = nil
+		if err != nil {
+			s.err = err
+			return false
+		}
+
+		s.source = string(buf)
+		s.pieces = s.split(span{0, len(s.source)}, 0)
+		s.chunks = s.merge()
+	}
+
+	if len(s.chunks) == 0 {
+		s.cursor = Chunk{}
+		return false
+	}
+
+	seq := make([]Sentence, len(s.chunks[0]))
+	for i, at := range s.chunks[0] {
+		x := s.pieces[at]
+		seq[i] = Sentence{
+			Index:  at,
+			Offset: x.lo,
+			Length: x.hi - x.lo,
+			Text:   s.source[x.lo:x.hi],
+		}
+	}
+
+	s.chunks = s.chunks[1:]
+	s.cursor = Chunk{
+		ID:        chunkID(0, seq[0].Index),
+		Sentences: seq,
+	}
+
+	return true
+}
+
+// split recursively the text until each piece fits the size
+func (s *Recursive) split(text span, level int) []span {
+	if s.length(text) <= s.confSize || level >= len(s.confSeparators) {
+		if s.length(text) == 0 {
+			return nil
+		}
+		return []span{text}
+	}
+
+	r := NewSentenceSpans(strings.NewReader(s.source[text.lo:text.hi]))
+	r.Split(s.confSeparators[level])
+
+	seq := make([]span, 0)
+	for r.Scan() {
+		lo, hi := r.Span()
+		seq = append(seq, s.split(span{text.lo + lo, text.lo + hi}, level+1)...)
+	}
+
+	// split function fails (e.g. too long token), use the next one
+	if err := r.Err(); err != nil {
+		return s.split(text, level+1)
+	}
+
+	return seq
+}
+
+// merge neighbouring pieces into chunks, chunk is the list of pieces
+func (s *Recursive) merge() [][]int {
+	chunks := make([][]int, 0)
+	chunk := make([]int, 0)
+
+	for i, x := range s.pieces {
+		if len(chunk) > 0 && s.length(span{s.pieces[chunk[0]].lo, x.hi}) > s.confSize {
+			chunks = append(chunks, chunk)
+
+			// trailing pieces of the chunk are shared with the next one
+			last := s.pieces[chunk[len(chunk)-1]]
+			k := len(chunk)
+			for k > 0 &&
+				s.length(span{s.pieces[chunk[k-1]].lo, last.hi}) <= s.confOverlap &&
+				s.length(span{s.pieces[chunk[k-1]].lo, x.hi}) <= s.confSize {
+				k--
+			}
+			chunk = append(make([]int, 0), chunk[k:]...)
+		}
+
+		chunk = append(chunk, i)
+	}
+
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// length of the text in characters
+func (s *Recursive) length(text span) int {
+	return utf8.RuneCountInString(s.source[text.lo:text.hi])
+}
+
+// ScanParagraph is a split function for a [bufio.Scanner] that returns each
+// paragraph of text, paragraphs are separated by blank lines. It will never
+// return an empty string.
+func ScanParagraph(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start := skipSpace(data, 0)
+	if atEOF && start == len(data) {
+		return len(data), nil, nil
+	}
+
+	// Scan until blank line \n\s*\n
+	for i := start; i < len(data); i++ {
+		if data[i] != '\n' {
+			continue
+		}
+
+		j := i + 1
+		for j < len(data) && (data[j] == ' ' || data[j] == '\t' || data[j] == '\r') {
+			j++
+		}
+
+		if j < len(data) && data[j] == '\n' {
+			return j + 1, trimRight(data[start:i]), nil
+		}
+	}
+
+	// If we're at EOF, we have a final paragraph. Return it.
+	if atEOF {
+		return len(data), trimRight(data[start:]), nil
+	}
+
+	// Request more data.
+	return 0, nil, nil
+}
+
+func trimRight(data []byte) []byte {
+	end := len(data)
+	for end > 0 {
+		r, width := utf8.DecodeLastRune(data[:end])
+		if !isSpace(r) {
+			break
+		}
+		end -= width
+	}
+	return data[:end]
+}
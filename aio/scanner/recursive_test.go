@@ -0,0 +1,102 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner_test
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio/scanner"
+)
+
+func TestRecursive(t *testing.T) {
+	text := "Aaa bbb. Ccc ddd.\n\nEee fff ggg hhh iii jjj.\nKkk."
+
+	t.Run("Paragraphs", func(t *testing.T) {
+		s := scanner.NewRecursive(strings.NewReader(text))
+		s.Size(30)
+		s.Overlap(0)
+
+		it.Then(t).Should(
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("Aaa bbb. Ccc ddd."),
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("Eee fff ggg hhh iii jjj.\nKkk."),
+		)
+
+		it.Then(t).ShouldNot(
+			it.True(s.Scan()),
+		)
+	})
+
+	t.Run("Overlap", func(t *testing.T) {
+		s := scanner.NewRecursive(strings.NewReader(text))
+		s.Size(16)
+		s.Overlap(8)
+
+		chunks := make([][]string, 0)
+		ids := make([]string, 0)
+		for s.Scan() {
+			c := s.Chunk()
+			ids = append(ids, c.ID)
+			for _, x := range c.Sentences {
+				it.Then(t).Should(it.Equal(text[x.Offset:x.Offset+x.Length], x.Text))
+			}
+			chunks = append(chunks, c.Text())
+		}
+
+		it.Then(t).Should(
+			it.Nil(s.Err()),
+			it.Equal(len(chunks), 5),
+			it.Seq(chunks[0]).Equal("Aaa bbb."),
+			it.Seq(chunks[1]).Equal("Ccc ddd.", "Eee"),
+			it.Seq(chunks[2]).Equal("Eee", "fff", "ggg", "hhh"),
+			it.Seq(chunks[3]).Equal("ggg", "hhh", "iii", "jjj."),
+			it.Seq(chunks[4]).Equal("iii", "jjj.", "Kkk."),
+			it.Seq(ids).Equal("0:0", "0:1", "0:2", "0:4", "0:6"),
+		)
+	})
+
+	t.Run("Separators", func(t *testing.T) {
+		s := scanner.NewRecursive(strings.NewReader("abcdef"))
+		s.Separators(bufio.ScanRunes)
+		s.Size(4)
+		s.Overlap(1)
+
+		it.Then(t).Should(
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("a", "b", "c", "d"),
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("d", "e", "f"),
+		)
+	})
+}
+
+func TestSplitParagraph(t *testing.T) {
+	for input, expected := range map[string][]string{
+		"Hello World!":                {"Hello World!"},
+		"Hello!\nWorld.":              {"Hello!\nWorld."},
+		"\n\nHello!\n\nWorld.\n":      {"Hello!", "World."},
+		"Hello! \n \t\n\n  World.\n ": {"Hello!", "World."},
+	} {
+		s := bufio.NewScanner(strings.NewReader(input))
+		s.Split(scanner.ScanParagraph)
+
+		seq := make([]string, 0)
+		for s.Scan() {
+			seq = append(seq, s.Text())
+		}
+
+		it.Then(t).Should(
+			it.Seq(seq).Equal(expected...),
+		)
+	}
+}
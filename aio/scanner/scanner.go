@@ -10,7 +10,7 @@ package scanner
 
 import (
 	"context"
-	"fmt"
+	"strings"
 
 	"github.com/kshard/embeddings"
 )
@@ -32,7 +32,20 @@ import (
 // The scanner uses embeddings to determine similarity. Use Similarity method
 // to change the default high cosine similarity to own implementation.
 // The module provides high, medium, weak and dissimilarity functions based on
-// cosine distance.
+// cosine distance. Vectors of different dimensions are never similar.
+//
+// The chunk is available either as text through [Scanner.Text] or as
+// [Chunk] through [Scanner.Chunk]. The chunk carries sentences, their
+// position in the source, embedding vectors and centroid of the chunk.
+//
+// The scanner never groups sentences of different sections into the same
+// chunk if the Reader implements Sectioner interface (e.g. [Markdown]).
+//
+// Use Hierarchy method to emit parent chunks of merged neighbouring chunks
+// for small-to-big retrieval.
+//
+// Short sentences embed noisily. Use BufferSize method to embed each sentence
+// together with its neighbours. The scanner still emits original sentences.
 //
 // Scanning stops unrecoverably at EOF or the first I/O error.
 type Scanner struct {
@@ -40,13 +53,26 @@ type Scanner struct {
 	confSimilarity        func([]float32, []float32) bool
 	confWindowInSentences int
 	confSimilarityWith    SimilarityWith
+	confBufferSize        int
+	confMemoSize          int
+	confWorkers           int
+	confHierarchy         []int
 	scanner               Reader
 	err                   error
 	eof                   bool
-	window                []embeddings.Embedding
-	cursor                []string
+	drained               bool
+	index                 int
+	pending               []Sentence
+	history               []Sentence
+	memo                  *memo
+	window                []Sentence
+	parents               []parent
+	queue                 []Chunk
+	cursor                Chunk
 }
 
+var _ Chunker = (*Scanner)(nil)
+
 // Reader is an interface similar to [bufio.Scanner].
 // It defines core functionality used by semantic chunking.
 type Reader interface {
@@ -62,8 +88,12 @@ func New(embed embeddings.Embedder, r Reader) *Scanner {
 		confSimilarity:        HighSimilarity,
 		confWindowInSentences: 32,
 		confSimilarityWith:    SIMILARITY_WITH_TAIL,
+		confWorkers:           1,
 		scanner:               r,
-		window:                make([]embeddings.Embedding, 0),
+		pending:               make([]Sentence, 0),
+		history:               make([]Sentence, 0),
+		window:                make([]Sentence, 0),
+		queue:                 make([]Chunk, 0),
 	}
 }
 
@@ -81,6 +111,12 @@ func (s *Scanner) Similarity(f func([]float32, []float32) bool) {
 //
 // Using SIMILARITY_WITH_TAIL configures algorithm to sort chunk similar
 // to the last element of chunk. The last element is changed after new one is added to chunk.
+//
+// Using SIMILARITY_WITH_CENTROID configures algorithm to sort chunk similar
+// to the mean vector of chunk. The mean vector is updated after new one is added to chunk.
+//
+// Using SIMILARITY_WITH_ALL configures algorithm to sort chunk similar
+// to every element of chunk (max-linkage).
 func (s *Scanner) SimilarityWith(x SimilarityWith) {
 	s.confSimilarityWith = x
 }
@@ -91,81 +127,230 @@ func (s *Scanner) Window(n int) {
 	s.confWindowInSentences = n
 }
 
+// MemoSize defines number of embeddings memorized by the scanner, sentences
+// repeated within the memory are not embedded again. The default value is 0,
+// the memory is as large as the context window.
+func (s *Scanner) MemoSize(n int) {
+	s.confMemoSize = n
+}
+
+// BufferSize defines number of neighbour sentences (before and after) combined
+// with the sentence to calculate its embedding. The combined vector is used for
+// similarity while the original sentence is emitted within the chunk. Vectors
+// of emitted sentences and centroids of chunks are combined vectors, embed
+// sentences again if their own vectors are stored.
+// The default value is 0, each sentence is embedded alone.
+func (s *Scanner) BufferSize(k int) {
+	s.confBufferSize = k
+}
+
+// Workers defines number of concurrent embedding requests used to fill
+// the context window. The embedder must be safe for concurrent use.
+// The batch api is used instead if embedder implements [embeddings.BatchEmbedder].
+// The default value is 1, sentences are embedded sequentially.
+func (s *Scanner) Workers(n int) {
+	s.confWorkers = n
+}
+
 func (s *Scanner) Err() error     { return s.err }
-func (s *Scanner) Text() []string { return s.cursor }
+func (s *Scanner) Text() []string { return s.cursor.Text() }
+func (s *Scanner) Chunk() Chunk   { return s.cursor }
 
 // Scan advances the Scanner through context window, sequences will be available
-// through [Scanner.Text]. It returns false if there was I/O error or EOF is reached.
+// through [Scanner.Text] and [Scanner.Chunk]. It returns false if there was
+// I/O error or EOF is reached.
 func (s *Scanner) Scan() bool {
 	if s.err != nil {
 		return false
 	}
 
-	if !s.eof {
-		s.eof, s.err = s.fill()
-		if s.err != nil {
-			return false
+	for len(s.queue) == 0 {
+		if !s.eof {
+			s.eof, s.err = s.fill()
+			if s.err != nil {
+				return false
+			}
 		}
+
+		chunk := s.peek()
+		if len(chunk.Sentences) == 0 {
+			s.flush()
+			if len(s.queue) == 0 {
+				s.cursor = Chunk{}
+				return false
+			}
+			break
+		}
+
+		s.lift(&chunk)
+		s.queue = append(s.queue, chunk)
 	}
 
-	s.cursor = s.peek()
+	s.cursor = s.queue[0]
+	s.queue = s.queue[1:]
 
-	return !(s.eof && len(s.cursor) == 0)
+	return true
 }
 
 // fill the window
 func (s *Scanner) fill() (bool, error) {
 	wn := s.confWindowInSentences - len(s.window)
-	for wn > 0 && s.scanner.Scan() {
-		txt := s.scanner.Text()
-		v32, err := s.embed.Embedding(context.Background(), txt)
-		if err != nil {
-			return false, fmt.Errorf("embedding has failed: %w, for {%s}", err, txt)
+	seq := make([]Sentence, 0, max(wn, 0))
+	txt := make([]string, 0, max(wn, 0))
+
+	// the window never spans multiple sections
+	section := -1
+	if len(s.window) > 0 {
+		section = s.window[0].Section.Index
+	}
+
+	for wn > 0 {
+		if err := s.readahead(); err != nil {
+			return false, err
+		}
+
+		if len(s.pending) == 0 {
+			break
 		}
 
-		s.window = append(s.window, v32)
+		sentence := s.pending[0]
+		if section == -1 {
+			section = sentence.Section.Index
+		}
+		if sentence.Section.Index != section {
+			break
+		}
+		s.pending = s.pending[1:]
+
+		txt = append(txt, s.buffered(sentence))
+		seq = append(seq, sentence)
 		wn--
 	}
 
-	if err := s.scanner.Err(); err != nil {
+	vec, err := s.embedding(txt)
+	if err != nil {
 		return false, err
 	}
 
-	return wn != 0, nil
+	for i := range seq {
+		seq[i].Vector = vec[txt[i]]
+	}
+
+	s.window = append(s.window, seq...)
+
+	return s.drained && len(s.pending) == 0, nil
 }
 
-// peek similar from the window
-func (s *Scanner) peek() []string {
-	if len(s.window) == 0 {
-		return nil
+// read sentences ahead of the window as required by the buffer
+func (s *Scanner) readahead() error {
+	for !s.drained && len(s.pending) <= s.confBufferSize {
+		if !s.scanner.Scan() {
+			s.drained = true
+			return s.scanner.Err()
+		}
+
+		sentence := Sentence{
+			Index: s.index,
+			Text:  s.scanner.Text(),
+		}
+		if r, ok := s.scanner.(Spanner); ok {
+			lo, hi := r.Span()
+			sentence.Offset, sentence.Length = lo, hi-lo
+		}
+		if r, ok := s.scanner.(Sectioner); ok {
+			sentence.Section = r.Section()
+		}
+
+		s.pending = append(s.pending, sentence)
+		s.index++
 	}
 
-	// split the window into similar (a) and non-similar (b) items
-	a, b := make([]embeddings.Embedding, 0), make([]embeddings.Embedding, 0)
-	a = append(a, s.window[0])
-
-	for i := 1; i < len(s.window); i++ {
-		var at int
-		switch s.confSimilarityWith {
-		case SIMILARITY_WITH_HEAD:
-			at = 0
-		case SIMILARITY_WITH_TAIL:
-			at = len(a) - 1
+	return nil
+}
+
+// combines the sentence with its neighbours from the same section,
+// the sentence is assumed to be removed from pending before the call.
+func (s *Scanner) buffered(sentence Sentence) string {
+	k := s.confBufferSize
+	if k <= 0 {
+		return sentence.Text
+	}
+
+	if len(s.history) > 0 && s.history[0].Section.Index != sentence.Section.Index {
+		s.history = s.history[:0]
+	}
+
+	seq := make([]string, 0, 2*k+1)
+	for _, x := range s.history {
+		seq = append(seq, x.Text)
+	}
+	seq = append(seq, sentence.Text)
+	for i := 0; i < k && i < len(s.pending); i++ {
+		if s.pending[i].Section.Index != sentence.Section.Index {
+			break
 		}
-		ref := a[at]
+		seq = append(seq, s.pending[i].Text)
+	}
+
+	s.history = append(s.history, sentence)
+	if len(s.history) > k {
+		s.history = s.history[len(s.history)-k:]
+	}
+
+	return strings.Join(seq, " ")
+}
 
-		if s.confSimilarity(ref.Vector, s.window[i].Vector) {
-			a = append(a, s.window[i])
-		} else {
-			b = append(b, s.window[i])
+// calculates embeddings of texts, each text is embedded only once
+func (s *Scanner) embedding(txt []string) (map[string][]float32, error) {
+	if s.memo == nil {
+		n := s.confMemoSize
+		if n <= 0 {
+			n = s.confWindowInSentences
 		}
+		s.memo = newMemo(n)
 	}
 
-	s.window = b
+	val := make(map[string][]float32)
+	seq := make([]string, 0, len(txt))
+	for _, x := range txt {
+		if _, has := val[x]; has {
+			continue
+		}
+		if v, has := s.memo.get(x); has {
+			val[x] = v
+			continue
+		}
+		val[x] = nil
+		seq = append(seq, x)
+	}
+
+	vec, err := prefetch(context.Background(), s.embed, s.confWorkers, seq)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, x := range seq {
+		val[x] = vec[i]
+		s.memo.put(x, vec[i])
+	}
+
+	return val, nil
+}
 
-	seq := make([]string, len(a))
-	for i, x := range a {
-		seq[i] = x.Text
+// peek similar from the window
+func (s *Scanner) peek() Chunk {
+	if len(s.window) == 0 {
+		return Chunk{}
 	}
-	return seq
+
+	// split the window into similar (a) and non-similar (b) items
+	a, b := split(s.window,
+		func(x Sentence) []float32 { return x.Vector },
+		s.confSimilarityWith,
+		s.confSimilarity,
+	)
+
+	s.window = b
+
+	return newChunk(a)
 }
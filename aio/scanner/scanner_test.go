@@ -39,6 +39,90 @@ func TestScanner(t *testing.T) {
 	)
 }
 
+func TestScannerChunk(t *testing.T) {
+	text := "a. bb. c. ddd. ff."
+
+	s := scanner.New(embed{}, scanner.NewSentenceSpans(strings.NewReader(text)))
+	s.Similarity(similar)
+	s.Window(3)
+
+	it.Then(t).Should(
+		it.True(s.Scan()),
+	)
+
+	c := s.Chunk()
+	it.Then(t).Should(
+		it.Seq(c.Text()).Equal("a.", "c."),
+		it.Equal(len(c.Sentences), 2),
+		it.Equal(c.Sentences[0].Index, 0),
+		it.Equal(c.Sentences[0].Offset, 0),
+		it.Equal(c.Sentences[0].Length, 2),
+		it.Equal(c.Sentences[1].Index, 2),
+		it.Equal(c.Sentences[1].Offset, 7),
+		it.Equal(c.Sentences[1].Length, 2),
+		it.Seq(c.Sentences[1].Vector).Equal(2.0),
+		it.Seq(c.Centroid).Equal(2.0),
+		it.Equal(c.Similarity, 1.0),
+	)
+}
+
+func TestScannerBufferSize(t *testing.T) {
+	text := "a. bb. c. a. bb. c."
+	e := &recorder{}
+
+	s := scanner.New(e, scanner.NewSentences(strings.NewReader(text)))
+	s.Similarity(func(a, b []float32) bool { return true })
+	s.BufferSize(1)
+	s.Window(6)
+
+	it.Then(t).Should(
+		it.True(s.Scan()),
+		it.Seq(s.Text()).Equal("a.", "bb.", "c.", "a.", "bb.", "c."),
+		it.Seq(e.seq).Equal(
+			"a. bb.",
+			"a. bb. c.",
+			"bb. c. a.",
+			"c. a. bb.",
+			"bb. c.",
+		),
+	)
+
+	it.Then(t).ShouldNot(
+		it.True(s.Scan()),
+	)
+}
+
+func TestScannerMemoSize(t *testing.T) {
+	text := "a. bb. ccc. a. bb. ccc."
+	e := &recorder{}
+
+	s := scanner.New(e, scanner.NewSentences(strings.NewReader(text)))
+	s.Similarity(func(a, b []float32) bool { return false })
+	s.MemoSize(3)
+	s.Window(2)
+
+	for s.Scan() {
+	}
+
+	it.Then(t).Should(
+		it.Nil(s.Err()),
+		it.Seq(e.seq).Equal("a.", "bb.", "ccc."),
+	)
+}
+
+func TestScannerCentroidDimensions(t *testing.T) {
+	s := scanner.New(dims{}, scanner.NewSentences(strings.NewReader("a. b. cc.")))
+	s.Similarity(func(a, b []float32) bool { return true })
+	s.SimilarityWith(scanner.SIMILARITY_WITH_CENTROID)
+
+	it.Then(t).Should(
+		it.True(s.Scan()),
+		it.Seq(s.Text()).Equal("a.", "b."),
+		it.True(s.Scan()),
+		it.Seq(s.Text()).Equal("cc."),
+	)
+}
+
 //------------------------------------------------------------------------------
 
 type embed struct{}
@@ -52,3 +136,23 @@ func (embed) Embedding(ctx context.Context, text string) (embeddings.Embedding,
 }
 
 func similar(a, b []float32) bool { return a[0] == b[0] }
+
+type recorder struct{ seq []string }
+
+func (*recorder) UsedTokens() int { return 0 }
+func (r *recorder) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	r.seq = append(r.seq, text)
+	return embed{}.Embedding(ctx, text)
+}
+
+// vector dimensions are defined by the length of text
+type dims struct{}
+
+func (dims) UsedTokens() int { return 0 }
+func (dims) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
+	v := make([]float32, len(text))
+	for i := range v {
+		v[i] = 1
+	}
+	return embeddings.Embedding{Text: text, Vector: v}, nil
+}
@@ -14,6 +14,18 @@ import (
 	"unicode/utf8"
 )
 
+// Sentences is [bufio.Scanner] that keeps track of sentences position
+// within the source. Position of the latest sentence is available through
+// [Sentences.Span]. Use it with Scanner to obtain offsets of chunks.
+type Sentences struct {
+	*bufio.Scanner
+	split  bufio.SplitFunc
+	pos    int
+	lo, hi int
+}
+
+var _ Spanner = (*Sentences)(nil)
+
 // Creates instance of [bufio.Scanner] configured for naïve sentence scanning.
 func NewSentences(r io.Reader) *bufio.Scanner {
 	s := bufio.NewScanner(r)
@@ -21,6 +33,44 @@ func NewSentences(r io.Reader) *bufio.Scanner {
 	return s
 }
 
+// Creates instance of [Sentences] configured for naïve sentence scanning.
+func NewSentenceSpans(r io.Reader) *Sentences {
+	s := &Sentences{Scanner: bufio.NewScanner(r)}
+	s.Split(ScanSentence)
+	return s
+}
+
+// Split sets the split function for the scanner, see [bufio.Scanner.Split].
+func (s *Sentences) Split(split bufio.SplitFunc) {
+	s.split = split
+	s.Scanner.Split(s.track)
+}
+
+// Span returns byte offsets [lo, hi) of the latest sentence within the source.
+func (s *Sentences) Span() (int, int) { return s.lo, s.hi }
+
+// track position of tokens produced by the split function
+func (s *Sentences) track(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	advance, token, err = s.split(data, atEOF)
+	if err != nil {
+		return
+	}
+
+	if token != nil {
+		// token is usually a sub-slice of data, its offset is derived from
+		// capacity. Otherwise, token is assumed to be the tail of advanced bytes.
+		at := cap(data) - cap(token)
+		if len(token) == 0 || at < 0 || at+len(token) > len(data) || &data[at] != &token[0] {
+			at = advance - len(token)
+		}
+
+		s.lo, s.hi = s.pos+at, s.pos+at+len(token)
+	}
+
+	s.pos += advance
+	return
+}
+
 // ScanSentence is a split function for a [bufio.Scanner] that returns each
 // sentence. It will never return an empty string.
 // The definition of space is set by `[.!?]\s+|\z`
@@ -39,3 +39,19 @@ func TestSplitSentences(t *testing.T) {
 		)
 	}
 }
+
+func TestSentencesSpan(t *testing.T) {
+	text := "Hello! \n World.  Hi"
+	s := scanner.NewSentenceSpans(strings.NewReader(text))
+
+	seq := make([]string, 0)
+	for s.Scan() {
+		lo, hi := s.Span()
+		seq = append(seq, text[lo:hi])
+		it.Then(t).Should(it.Equal(text[lo:hi], s.Text()))
+	}
+
+	it.Then(t).Should(
+		it.Seq(seq).Equal("Hello!", "World.", "Hi"),
+	)
+}
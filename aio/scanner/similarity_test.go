@@ -0,0 +1,40 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner_test
+
+import (
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio/scanner"
+)
+
+func TestSimilarity(t *testing.T) {
+	a := []float32{1, 0, 0}
+	b := []float32{0, 1, 0}
+	c := []float32{-1, 0, 0}
+
+	it.Then(t).Should(
+		it.True(scanner.HighSimilarity(a, a)),
+		it.True(scanner.MediumSimilarity(a, b)),
+		it.True(scanner.Dissimilar(a, c)),
+		it.True(scanner.RangeSimilarity(0.4, 0.6)(a, b)),
+		it.True(scanner.Dissimilar([]float32{0, 0, 0}, a)),
+		it.True(scanner.Dissimilar([]float32{0, 0, 0}, []float32{0, 0, 0})),
+	)
+
+	it.Then(t).ShouldNot(
+		it.True(scanner.HighSimilarity(a, []float32{1, 0})),
+		it.True(scanner.Dissimilar(a, []float32{1, 0})),
+		it.True(scanner.CosineSimilarity(func(float32) bool { return true })(a, nil)),
+		it.True(scanner.HighSimilarity([]float32{0, 0, 0}, a)),
+		it.True(scanner.MediumSimilarity([]float32{0, 0, 0}, a)),
+		it.True(scanner.HighSimilarity([]float32{0, 0, 0}, []float32{0, 0, 0})),
+	)
+}
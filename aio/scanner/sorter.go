@@ -10,7 +10,6 @@ package scanner
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/fogfish/golem/optics"
 	"github.com/fogfish/golem/trait/seq"
@@ -35,6 +34,7 @@ type Sorter[T any] struct {
 	confSimilarity        func([]float32, []float32) bool
 	confWindowInSentences int
 	confSimilarityWith    SimilarityWith
+	confWorkers           int
 	scanner               seq.Seq[T]
 	lens                  optics.Lens[T, string]
 	err                   error
@@ -43,15 +43,6 @@ type Sorter[T any] struct {
 	cursor                []T
 }
 
-// Configure similarity sorting algorithm
-type SimilarityWith int
-
-// Configure similarity sorting algorithm
-const (
-	SIMILARITY_WITH_HEAD SimilarityWith = iota
-	SIMILARITY_WITH_TAIL
-)
-
 type typed[T any] struct {
 	object T
 	vector []float32
@@ -64,6 +55,7 @@ func NewSorter[T any](embed embeddings.Embedder, lens optics.Lens[T, string], se
 		confSimilarity:        HighSimilarity,
 		confWindowInSentences: 32,
 		confSimilarityWith:    SIMILARITY_WITH_TAIL,
+		confWorkers:           1,
 		scanner:               seq,
 		lens:                  lens,
 		window:                make([]typed[T], 0),
@@ -84,6 +76,12 @@ func (s *Sorter[T]) Similarity(f func([]float32, []float32) bool) {
 //
 // Using SIMILARITY_WITH_TAIL configures algorithm to sort chunk similar
 // to the last element of chunk. The last element is changed after new one is added to chunk.
+//
+// Using SIMILARITY_WITH_CENTROID configures algorithm to sort chunk similar
+// to the mean vector of chunk. The mean vector is updated after new one is added to chunk.
+//
+// Using SIMILARITY_WITH_ALL configures algorithm to sort chunk similar
+// to every element of chunk (max-linkage).
 func (s *Sorter[T]) SimilarityWith(x SimilarityWith) {
 	s.confSimilarityWith = x
 }
@@ -94,6 +92,14 @@ func (s *Sorter[T]) Window(n int) {
 	s.confWindowInSentences = n
 }
 
+// Workers defines number of concurrent embedding requests used to fill
+// the context window. The embedder must be safe for concurrent use.
+// The batch api is used instead if embedder implements [embeddings.BatchEmbedder].
+// The default value is 1, objects are embedded sequentially.
+func (s *Sorter[T]) Workers(n int) {
+	s.confWorkers = n
+}
+
 func (s *Sorter[T]) Err() error { return s.err }
 func (s *Sorter[T]) Value() []T { return s.cursor }
 
@@ -120,19 +126,26 @@ func (s *Sorter[T]) Next() bool {
 func (s *Sorter[T]) fill() (bool, error) {
 	wn := s.confWindowInSentences - len(s.window)
 
+	seq := make([]T, 0, max(wn, 0))
+	txt := make([]string, 0, max(wn, 0))
+
 	has := s.scanner != nil
 	for ; wn > 0 && has; has = s.scanner.Next() {
 		obj := s.scanner.Value()
-		txt := s.lens.Get(&obj)
-		v32, err := s.embed.Embedding(context.Background(), txt)
-		if err != nil {
-			return false, fmt.Errorf("embedding has failed: %w, for {%s}", err, txt)
-		}
-
-		s.window = append(s.window, typed[T]{object: obj, vector: v32.Vector})
+		seq = append(seq, obj)
+		txt = append(txt, s.lens.Get(&obj))
 		wn--
 	}
 
+	vec, err := prefetch(context.Background(), s.embed, s.confWorkers, txt)
+	if err != nil {
+		return false, err
+	}
+
+	for i, obj := range seq {
+		s.window = append(s.window, typed[T]{object: obj, vector: vec[i]})
+	}
+
 	return !has || wn != 0, nil
 }
 
@@ -143,25 +156,11 @@ func (s *Sorter[T]) peek() []T {
 	}
 
 	// split the window into similar (a) and non-similar (b) items
-	a, b := make([]typed[T], 0), make([]typed[T], 0)
-	a = append(a, s.window[0])
-
-	for i := 1; i < len(s.window); i++ {
-		var at int
-		switch s.confSimilarityWith {
-		case SIMILARITY_WITH_HEAD:
-			at = 0
-		case SIMILARITY_WITH_TAIL:
-			at = len(a) - 1
-		}
-		ref := a[at]
-
-		if s.confSimilarity(ref.vector, s.window[i].vector) {
-			a = append(a, s.window[i])
-		} else {
-			b = append(b, s.window[i])
-		}
-	}
+	a, b := split(s.window,
+		func(x typed[T]) []float32 { return x.vector },
+		s.confSimilarityWith,
+		s.confSimilarity,
+	)
 
 	s.window = b
 
@@ -44,3 +44,28 @@ func TestSorter(t *testing.T) {
 		it.True(s.Next()),
 	)
 }
+
+func TestSorterSimilarityWith(t *testing.T) {
+	text := []obj{{"xxx"}, {"xxxx"}, {"xxxxx"}, {"xxxxxx"}, {"xx"}}
+	near := func(a, b []float32) bool { return a[0]-b[0] <= 1.5 && b[0]-a[0] <= 1.5 }
+
+	for with, expected := range map[scanner.SimilarityWith][]obj{
+		scanner.SIMILARITY_WITH_HEAD:     {{"xxx"}, {"xxxx"}, {"xx"}},
+		scanner.SIMILARITY_WITH_TAIL:     {{"xxx"}, {"xxxx"}, {"xxxxx"}, {"xxxxxx"}},
+		scanner.SIMILARITY_WITH_CENTROID: {{"xxx"}, {"xxxx"}, {"xxxxx"}},
+		scanner.SIMILARITY_WITH_ALL:      {{"xxx"}, {"xxxx"}},
+	} {
+		s := scanner.NewSorter(embed{},
+			optics.ForProduct1[obj, string](),
+			seq.FromSlice(text),
+		)
+		s.Similarity(near)
+		s.SimilarityWith(with)
+		s.Window(5)
+
+		it.Then(t).Should(
+			it.True(s.Next()),
+			it.Seq(s.Value()).Equal(expected...),
+		)
+	}
+}
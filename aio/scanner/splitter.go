@@ -0,0 +1,403 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Common abbreviations of English language
+var AbbreviationsEN = []string{
+	"mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st", "mt", "rev", "gen", "col",
+	"capt", "lt", "sgt", "gov", "sen", "rep", "hon",
+	"e.g", "i.e", "etc", "vs", "cf", "al", "approx", "ca", "viz",
+	"inc", "ltd", "co", "corp", "dept", "est", "fig", "figs", "eq", "vol", "ed", "p", "pp",
+	"jan", "feb", "mar", "apr", "jun", "jul", "aug", "sep", "sept", "oct", "nov", "dec",
+	"mon", "tue", "wed", "thu", "fri", "sat", "sun",
+}
+
+// Common abbreviations of German language
+var AbbreviationsDE = []string{
+	"hr", "fr", "dr", "prof", "nr", "str", "bzw", "usw", "ca", "vgl", "ggf", "evtl",
+	"z.b", "u.a", "d.h", "u.u", "s.o", "s.u", "o.ä", "inkl", "zzgl", "bspw", "sog",
+}
+
+// Common abbreviations of French language
+var AbbreviationsFR = []string{
+	"m", "mm", "mme", "mlle", "dr", "pr", "me", "st", "ste",
+	"etc", "cf", "env", "p.ex", "c.-à-d", "av", "bd", "chap", "éd",
+}
+
+// Common abbreviations of Spanish language
+var AbbreviationsES = []string{
+	"sr", "sra", "srta", "dr", "dra", "lic", "ing", "ud", "uds",
+	"etc", "p.ej", "aprox", "av", "pág", "cap", "núm",
+}
+
+// Common words starting English sentences, initials and acronyms followed
+// by them end the sentence (e.g. "in the U.S. He said").
+var sentenceStarters = map[string]struct{}{
+	"I": {}, "He": {}, "She": {}, "It": {}, "We": {}, "They": {}, "You": {},
+	"The": {}, "This": {}, "That": {}, "These": {}, "Those": {}, "There": {},
+	"Then": {}, "But": {}, "And": {}, "If": {}, "When": {}, "What": {},
+}
+
+// Splitter is a sentence splitter aware of abbreviations and numbers.
+// Use [Splitter.Split] as split function for a [bufio.Scanner].
+//
+// The sentence ends with [.!?…] followed by white space. Closing quotes and
+// brackets after the terminator belong to the sentence. The splitter does not
+// break the sentence:
+//   - after abbreviations (e.g. "Dr. Smith"), the list is configurable;
+//   - after initials (e.g. "J. R. Smith");
+//   - after number that opens the line (e.g. numbered list "3. item");
+//   - inside decimals and after acronyms (e.g. "3.14", "U.S. Army"), unless
+//     common sentence starter follows (e.g. "the U.S. He said");
+//   - if the next word starts with lower case letter (e.g. "approx. five");
+//   - after ellipsis unless the next word starts with upper case letter.
+//
+// Each item of numbered list and each paragraph starts new sentence.
+//
+// Use [NewUnicodeSplitter] for multilingual text.
+type Splitter struct {
+	abbreviations map[string]struct{}
+	unicode       bool
+}
+
+// Creates new sentence splitter using lists of abbreviations.
+// Abbreviations are case insensitive, the trailing dot is optional.
+//
+//	s := scanner.NewSentenceSpans(r)
+//	s.Split(scanner.NewSplitter(scanner.AbbreviationsEN).Split)
+func NewSplitter(abbreviations ...[]string) *Splitter {
+	s := &Splitter{abbreviations: make(map[string]struct{})}
+	for _, seq := range abbreviations {
+		for _, x := range seq {
+			s.abbreviations[strings.ToLower(strings.TrimSuffix(x, "."))] = struct{}{}
+		}
+	}
+	return s
+}
+
+// Creates new sentence splitter aware of non-Latin terminators. Besides
+// [.!?…] it recognizes CJK and full-width terminators [。！？．｡], which do
+// not require white space after, Hindi danda [।॥], Arabic [؟۔], Armenian
+// [։], Greek [;] and Ethiopic, Myanmar terminators. The full-width full stop
+// between digits is the decimal point (e.g. "３．１４").
+func NewUnicodeSplitter(abbreviations ...[]string) *Splitter {
+	s := NewSplitter(abbreviations...)
+	s.unicode = true
+	return s
+}
+
+var unicodeSplitter = NewUnicodeSplitter()
+
+// ScanUnicodeSentence is a split function for a [bufio.Scanner] that returns
+// each sentence of multilingual text. It will never return an empty string.
+// See [NewUnicodeSplitter] for details.
+func ScanUnicodeSentence(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	return unicodeSplitter.Split(data, atEOF)
+}
+
+// Split is a split function for a [bufio.Scanner] that returns each sentence.
+// It will never return an empty string.
+func (s *Splitter) Split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	// Skip leading spaces.
+	start := skipSpace(data, 0)
+	if atEOF && start == len(data) {
+		return len(data), nil, nil
+	}
+
+	for i := start; i < len(data); {
+		r, width := utf8.DecodeRune(data[i:])
+
+		// Numbered list item starts new sentence even without terminator
+		if r == '\n' {
+			item, more := isListItem(data, i+width)
+			if more && !atEOF {
+				return 0, nil, nil
+			}
+			if item {
+				return i + width, trimRight(data[start:i]), nil
+			}
+		}
+
+		term, strong := s.isTerminator(r)
+		if !term || s.isDecimal(data, start, i, r) {
+			i += width
+			continue
+		}
+
+		// The terminator might be a sequence of runes (e.g. "?!", "...")
+		// followed by closing quotes and brackets.
+		end := i + width
+		for end < len(data) {
+			r, width := utf8.DecodeRune(data[end:])
+			term, hard := s.isTerminator(r)
+			if !term {
+				break
+			}
+			strong = strong || hard
+			end += width
+		}
+
+		for end < len(data) {
+			r, width := utf8.DecodeRune(data[end:])
+			if !s.isClosing(r) {
+				break
+			}
+			end += width
+		}
+
+		if end == len(data) {
+			break
+		}
+
+		// Terminators of no-space scripts do not require white space
+		if strong {
+			return end, data[start:end], nil
+		}
+
+		if r, _ := utf8.DecodeRune(data[end:]); !isSpace(r) {
+			i = end
+			continue
+		}
+
+		// The decision requires the next word
+		next := skipSpace(data, end)
+		if !atEOF && (next == len(data) || skipLetters(data, next) == len(data)) {
+			return 0, nil, nil
+		}
+
+		if isParagraph(data[end:next]) || s.isBoundary(data, start, i, data[next:]) {
+			return end, data[start:end], nil
+		}
+
+		i = end
+	}
+
+	// If we're at EOF, we have a final, non-terminated sentence. Return it.
+	if atEOF {
+		return len(data), trimRight(data[start:]), nil
+	}
+
+	// Request more data.
+	return 0, nil, nil
+}
+
+// checks if terminator at data[at] is the sentence boundary, the next is
+// the text after the terminator and spaces.
+func (s *Splitter) isBoundary(data []byte, start, at int, next []byte) bool {
+	ahead, _ := utf8.DecodeRune(next)
+	if len(next) == 0 {
+		ahead = 0
+	}
+
+	r, width := utf8.DecodeRune(data[at:])
+	if r != '.' && r != '…' {
+		return true
+	}
+
+	// ellipsis
+	if r == '…' || (at+width < len(data) && data[at+width] == '.') {
+		return ahead == 0 || unicode.IsUpper(ahead) || !unicode.IsLetter(ahead)
+	}
+
+	if unicode.IsLower(ahead) {
+		return false
+	}
+
+	// the word before the dot
+	from := at
+	for from > start {
+		r, width := utf8.DecodeLastRune(data[start:from])
+		if isSpace(r) || isOpening(r) {
+			break
+		}
+		from -= width
+	}
+	word := string(data[from:at])
+
+	if _, has := s.abbreviations[strings.ToLower(word)]; has {
+		return false
+	}
+
+	// initials and acronyms
+	if r, width := utf8.DecodeRuneInString(word); (width == len(word) && unicode.IsUpper(r)) || isAcronym(word) {
+		return isStarter(next)
+	}
+
+	// numbered list
+	if len(word) > 0 && strings.IndexFunc(word, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+		if from == start || data[from-1] == '\n' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checks if the full-width full stop at data[at] is the decimal point,
+// the dot of no-space scripts does not require white space after it.
+func (s *Splitter) isDecimal(data []byte, start, at int, r rune) bool {
+	if r != '．' || at == start {
+		return false
+	}
+
+	prev, _ := utf8.DecodeLastRune(data[start:at])
+	next, _ := utf8.DecodeRune(data[at+utf8.RuneLen(r):])
+	return unicode.IsDigit(prev) && unicode.IsDigit(next)
+}
+
+// checks if the word is acronym of single letters separated by dots
+// (e.g. "U.S", "U.K.")
+func isAcronym(word string) bool {
+	if !strings.Contains(word, ".") {
+		return false
+	}
+
+	for _, x := range strings.Split(strings.TrimSuffix(word, "."), ".") {
+		r, width := utf8.DecodeRuneInString(x)
+		if width != len(x) || !unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// checks if the text starts with common sentence starter
+func isStarter(text []byte) bool {
+	_, has := sentenceStarters[string(text[:skipLetters(text, 0)])]
+	return has
+}
+
+// checks if the white space contains blank line
+func isParagraph(space []byte) bool {
+	lines := 0
+	for _, c := range space {
+		if c == '\n' {
+			lines++
+		}
+	}
+	return lines > 1
+}
+
+// checks if the line at the position starts with numbered list marker
+// (e.g. "3. ", "4) "), the second result is true if more data is required.
+func isListItem(data []byte, at int) (bool, bool) {
+	for at < len(data) && (data[at] == ' ' || data[at] == '\t') {
+		at++
+	}
+
+	digits := 0
+	for at < len(data) && '0' <= data[at] && data[at] <= '9' {
+		digits++
+		at++
+	}
+
+	switch {
+	case at+1 >= len(data):
+		return false, true
+	case digits == 0:
+		return false, false
+	case data[at] != '.' && data[at] != ')':
+		return false, false
+	default:
+		r, _ := utf8.DecodeRune(data[at+1:])
+		return isSpace(r), false
+	}
+}
+
+// skip letters starting from the position
+func skipLetters(data []byte, at int) int {
+	for width := 0; at < len(data); at += width {
+		var r rune
+		r, width = utf8.DecodeRune(data[at:])
+		if !unicode.IsLetter(r) {
+			break
+		}
+	}
+	return at
+}
+
+// skip spaces starting from the position
+func skipSpace(data []byte, at int) int {
+	for width := 0; at < len(data); at += width {
+		var r rune
+		r, width = utf8.DecodeRune(data[at:])
+		if !isSpace(r) {
+			break
+		}
+	}
+	return at
+}
+
+// checks if rune is the terminator, strong terminator does not require
+// white space after it.
+func (s *Splitter) isTerminator(r rune) (bool, bool) {
+	switch r {
+	case '.', '!', '?', '…':
+		return true, false
+	}
+
+	if !s.unicode {
+		return false, false
+	}
+
+	switch r {
+	// CJK and full-width forms
+	case '。', '！', '？', '．', '｡', '︒', '﹒', '﹗', '﹖':
+		return true, true
+	// Devanagari danda, double danda
+	case '।', '॥':
+		return true, false
+	// Arabic question mark, Urdu full stop
+	case '؟', '۔':
+		return true, false
+	// Armenian full stop, emphasis and question marks are inside the word
+	case '։':
+		return true, false
+	// Greek question mark
+	case '\u037E':
+		return true, false
+	// Ethiopic full stop, question mark; Myanmar section
+	case '።', '፧', '။':
+		return true, false
+	}
+
+	return false, false
+}
+
+func (s *Splitter) isClosing(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '}', '»', '›', '”', '’':
+		return true
+	}
+
+	if !s.unicode {
+		return false
+	}
+
+	switch r {
+	case '」', '』', '）', '】', '〉', '》', '〕', '〗', '〙', '〛', '＂', '＇', '］', '｝', '｣':
+		return true
+	}
+
+	return false
+}
+
+func isOpening(r rune) bool {
+	switch r {
+	case '"', '\'', '(', '[', '{', '«', '‹', '“', '‘':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner_test
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio/scanner"
+)
+
+func TestSplitter(t *testing.T) {
+	split := scanner.NewSplitter(scanner.AbbreviationsEN).Split
+
+	for input, expected := range map[string][]string{
+		"Hello World!":                      {"Hello World!"},
+		"Hello! World.":                     {"Hello!", "World."},
+		"Hello!\nWorld.  ":                  {"Hello!", "World."},
+		"Hello 3.14 World!":                 {"Hello 3.14 World!"},
+		"Dr. Smith is here. He waits.":      {"Dr. Smith is here.", "He waits."},
+		"Use e.g. this one. Or not.":        {"Use e.g. this one.", "Or not."},
+		"The U.S. policy changed. Why?":     {"The U.S. policy changed.", "Why?"},
+		"J. R. R. Tolkien wrote it. Yes.":   {"J. R. R. Tolkien wrote it.", "Yes."},
+		"3. First item\n4. Second item":     {"3. First item", "4. Second item"},
+		"3. a\n4. b":                        {"3. a", "4. b"},
+		"Steps:\n  1) cut\n  2) cook":       {"Steps:", "1) cut", "2) cook"},
+		"The U.S. Army marched. It won.":    {"The U.S. Army marched.", "It won."},
+		"Visit the U.K. Then go home.":      {"Visit the U.K.", "Then go home."},
+		"He lives in the U.S. He likes it.": {"He lives in the U.S.", "He likes it."},
+		"Plan B. It works.":                 {"Plan B.", "It works."},
+		"A.\n\nB.":                          {"A.", "B."},
+		"It costs approx.\n\nFive.":         {"It costs approx.", "Five."},
+		"It was 1999\nand then 2000.":       {"It was 1999\nand then 2000."},
+		"It is chapter 3. Then it ends.":    {"It is chapter 3.", "Then it ends."},
+		"Wait... what? No... Really!":       {"Wait... what?", "No...", "Really!"},
+		"Wait… what? Fine… Go.":             {"Wait… what?", "Fine…", "Go."},
+		`He said "Stop." Then left.`:        {`He said "Stop."`, "Then left."},
+		"It works (mostly.) Next one.":      {"It works (mostly.)", "Next one."},
+		"Really?! Yes.":                     {"Really?!", "Yes."},
+		"It costs approx. five dollars.":    {"It costs approx. five dollars."},
+		"See Fig. 3 for details. Thanks.":   {"See Fig. 3 for details.", "Thanks."},
+		"   ":                               {},
+	} {
+		s := bufio.NewScanner(strings.NewReader(input))
+		s.Split(split)
+
+		seq := make([]string, 0)
+		for s.Scan() {
+			seq = append(seq, s.Text())
+		}
+
+		it.Then(t).Should(
+			it.Seq(seq).Equal(expected...),
+		)
+	}
+}
+
+func TestSplitterSpan(t *testing.T) {
+	text := "Dr. Smith is here.  He waits."
+
+	s := scanner.NewSentenceSpans(strings.NewReader(text))
+	s.Split(scanner.NewSplitter(scanner.AbbreviationsEN).Split)
+
+	seq := make([]string, 0)
+	for s.Scan() {
+		lo, hi := s.Span()
+		seq = append(seq, text[lo:hi])
+	}
+
+	it.Then(t).Should(
+		it.Seq(seq).Equal("Dr. Smith is here.", "He waits."),
+	)
+}
+
+func TestUnicodeSplitter(t *testing.T) {
+	for input, expected := range map[string][]string{
+		"Hello! World.":    {"Hello!", "World."},
+		"你好。世界！你好吗？":       {"你好。", "世界！", "你好吗？"},
+		"「こんにちは。」と言った。はい！": {"「こんにちは。」", "と言った。", "はい！"},
+		"全角．終わり":           {"全角．", "終わり"},
+		"円周率は３．１４です。はい":    {"円周率は３．１４です。", "はい"},
+		"Ինչո՜ւ։ Այո՛։":    {"Ինչո՜ւ։", "Այո՛։"},
+		"यह एक वाक्य है। यह दूसरा है॥": {"यह एक वाक्य है।", "यह दूसरा है॥"},
+		"كيف حالك؟ أنا بخير.":          {"كيف حالك؟", "أنا بخير."},
+		"Բարեւ։ Ինչո՞ւ ես այստեղ։":     {"Բարեւ։", "Ինչո՞ւ ես այստեղ։"},
+		"Τι κάνεις; Καλά.":             {"Τι κάνεις;", "Καλά."},
+		"Mixed 中文。And English. Done":   {"Mixed 中文。", "And English.", "Done"},
+	} {
+		s := bufio.NewScanner(strings.NewReader(input))
+		s.Split(scanner.ScanUnicodeSentence)
+
+		seq := make([]string, 0)
+		for s.Scan() {
+			seq = append(seq, s.Text())
+		}
+
+		it.Then(t).Should(
+			it.Seq(seq).Equal(expected...),
+		)
+	}
+}
@@ -0,0 +1,324 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// TextTiling implements lexical segmentation of text (M. A. Hearst, 1997).
+// It does not require embeddings, the segmentation is based on lexical
+// cohesion of neighbouring blocks of sentences:
+//   - the gap between sentences is scored by cosine similarity of term
+//     vectors of blocks before and after the gap;
+//   - scores are smoothed by moving average;
+//   - the depth score of the gap is the distance from peaks on both sides;
+//   - gaps with depth above mean - σ/2 that are local maxima are boundaries.
+//
+// The chunk is available as [Chunk] through [TextTiling.Chunk], same as for
+// semantic [Scanner], embedding vectors are not calculated. It allows
+// pre-segmentation of huge archives and embedding of the final chunks only.
+//
+// Sections of the Reader (e.g. Markdown headings) are hard boundaries.
+// Use BlockSize and Smoothing methods to change default 4 sentences and
+// 1 sentence (radius of moving average) values.
+//
+// The source is read incrementally, each section is segmented within the
+// bounded window of sentences. The last chunk of the window is carried over
+// to the next window unless it occupies the whole window. Use Window method
+// to change default 1000 sentences value.
+type TextTiling struct {
+	confBlockSize int
+	confSmoothing int
+	confWindow    int
+	confStopWords map[string]struct{}
+	scanner       Reader
+	eof           bool
+	err           error
+	index         int
+	buffer        []Sentence
+	chunks        [][]Sentence
+	cursor        Chunk
+}
+
+var _ Chunker = (*TextTiling)(nil)
+
+// Common stop words of English language
+var StopWordsEN = []string{
+	"a", "about", "above", "after", "again", "against", "all", "am", "an", "and",
+	"any", "are", "as", "at", "be", "because", "been", "before", "being", "below",
+	"between", "both", "but", "by", "can", "could", "did", "do", "does", "doing",
+	"down", "during", "each", "few", "for", "from", "further", "had", "has",
+	"have", "having", "he", "her", "here", "hers", "herself", "him", "himself",
+	"his", "how", "i", "if", "in", "into", "is", "it", "its", "itself", "just",
+	"me", "more", "most", "my", "myself", "no", "nor", "not", "now", "of", "off",
+	"on", "once", "only", "or", "other", "our", "ours", "ourselves", "out",
+	"over", "own", "same", "she", "should", "so", "some", "such", "than", "that",
+	"the", "their", "theirs", "them", "themselves", "then", "there", "these",
+	"they", "this", "those", "through", "to", "too", "under", "until", "up",
+	"very", "was", "we", "were", "what", "when", "where", "which", "while", "who",
+	"whom", "why", "will", "with", "would", "you", "your", "yours", "yourself",
+	"yourselves",
+}
+
+// Creates new instance of TextTiling to read sentences from the Reader.
+func NewTextTiling(r Reader) *TextTiling {
+	s := &TextTiling{
+		confBlockSize: 4,
+		confSmoothing: 1,
+		confWindow:    1000,
+		scanner:       r,
+	}
+	s.StopWords(StopWordsEN)
+	return s
+}
+
+// BlockSize defines number of sentences in the block compared at each gap.
+// The default value is 4 sentences.
+func (s *TextTiling) BlockSize(k int) {
+	s.confBlockSize = max(k, 1)
+}
+
+// Smoothing defines radius of moving average applied to gap scores.
+// The default value is 1, use 0 to disable smoothing.
+func (s *TextTiling) Smoothing(n int) {
+	s.confSmoothing = max(n, 0)
+}
+
+// Window defines maximum number of sentences segmented at once, it bounds
+// the memory and the size of chunk. The default value is 1000 sentences.
+func (s *TextTiling) Window(n int) {
+	s.confWindow = max(n, 2)
+}
+
+// StopWords defines words excluded from term vectors.
+// The default is English stop words.
+func (s *TextTiling) StopWords(words []string) {
+	s.confStopWords = make(map[string]struct{})
+	for _, w := range words {
+		s.confStopWords[strings.ToLower(w)] = struct{}{}
+	}
+}
+
+func (s *TextTiling) Err() error     { return s.err }
+func (s *TextTiling) Text() []string { return s.cursor.Text() }
+func (s *TextTiling) Chunk() Chunk   { return s.cursor }
+
+// Scan advances to the next chunk, which will then be available through
+// [TextTiling.Text] and [TextTiling.Chunk]. It returns false if there was
+// I/O error or EOF is reached.
+func (s *TextTiling) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+
+	for len(s.chunks) == 0 {
+		if s.eof {
+			s.cursor = Chunk{}
+			return false
+		}
+
+		if s.err = s.fill(); s.err != nil {
+			return false
+		}
+	}
+
+	seq := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	s.cursor = Chunk{
+		ID:        chunkID(0, seq[0].Index),
+		Sentences: seq,
+		Section:   seq[0].Section,
+	}
+
+	return true
+}
+
+// read sentences until the end of section or the window is full
+func (s *TextTiling) fill() error {
+	for s.scanner.Scan() {
+		sentence := Sentence{
+			Index: s.index,
+			Text:  s.scanner.Text(),
+		}
+		s.index++
+		if r, ok := s.scanner.(Spanner); ok {
+			lo, hi := r.Span()
+			sentence.Offset, sentence.Length = lo, hi-lo
+		}
+		if r, ok := s.scanner.(Sectioner); ok {
+			sentence.Section = r.Section()
+		}
+
+		if len(s.buffer) > 0 && s.buffer[0].Section.Index != sentence.Section.Index {
+			s.chunks = append(s.chunks, s.tile(s.buffer)...)
+			s.buffer = []Sentence{sentence}
+			return nil
+		}
+
+		s.buffer = append(s.buffer, sentence)
+		if len(s.buffer) >= s.confWindow {
+			seq := s.tile(s.buffer)
+			if len(seq) == 1 {
+				s.chunks = append(s.chunks, seq...)
+				s.buffer = nil
+				return nil
+			}
+
+			// the last chunk might continue in the next window
+			s.chunks = append(s.chunks, seq[:len(seq)-1]...)
+			s.buffer = append([]Sentence(nil), seq[len(seq)-1]...)
+			return nil
+		}
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return err
+	}
+
+	s.eof = true
+	s.chunks = append(s.chunks, s.tile(s.buffer)...)
+	s.buffer = nil
+	return nil
+}
+
+// segment sentences into chunks using depth scores of gaps
+func (s *TextTiling) tile(seq []Sentence) [][]Sentence {
+	terms := make([]map[string]float64, len(seq))
+	for i, x := range seq {
+		terms[i] = s.terms(x.Text)
+	}
+
+	// score of gap i is between sentences i-1 and i
+	n := len(seq)
+	score := make([]float64, n)
+	for i := 1; i < n; i++ {
+		a := block(terms[max(0, i-s.confBlockSize):i])
+		b := block(terms[i:min(n, i+s.confBlockSize)])
+		score[i] = tfcosine(a, b)
+	}
+
+	score = smooth(score, s.confSmoothing)
+	depth := depths(score)
+
+	// cutoff is mean - σ/2 of depth scores
+	mean, std := 0.0, 0.0
+	if n > 1 {
+		for _, x := range depth[1:] {
+			mean += x
+		}
+		mean /= float64(n - 1)
+		for _, x := range depth[1:] {
+			std += (x - mean) * (x - mean)
+		}
+		std = math.Sqrt(std / float64(n-1))
+	}
+	cutoff := mean - std/2
+
+	chunks := make([][]Sentence, 0)
+	lo := 0
+	for i := 1; i < n; i++ {
+		isPeak := depth[i] > depth[i-1] && (i == n-1 || depth[i] >= depth[i+1])
+		if depth[i] > 0 && depth[i] > cutoff && isPeak {
+			chunks = append(chunks, seq[lo:i])
+			lo = i
+		}
+	}
+
+	if lo < n {
+		chunks = append(chunks, seq[lo:])
+	}
+
+	return chunks
+}
+
+// term vector of the text
+func (s *TextTiling) terms(text string) map[string]float64 {
+	tf := make(map[string]float64)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, w := range words {
+		if _, has := s.confStopWords[w]; has {
+			continue
+		}
+		tf[w]++
+	}
+
+	return tf
+}
+
+// term vector of the block
+func block(seq []map[string]float64) map[string]float64 {
+	tf := make(map[string]float64)
+	for _, x := range seq {
+		for w, f := range x {
+			tf[w] += f
+		}
+	}
+	return tf
+}
+
+// cosine similarity of term vectors
+func tfcosine(a, b map[string]float64) float64 {
+	ab, aa, bb := 0.0, 0.0, 0.0
+	for w, f := range a {
+		ab += f * b[w]
+		aa += f * f
+	}
+	for _, f := range b {
+		bb += f * f
+	}
+
+	if aa == 0 || bb == 0 {
+		return 0
+	}
+
+	return ab / (math.Sqrt(aa) * math.Sqrt(bb))
+}
+
+// moving average of gap scores, gap 0 is not defined
+func smooth(score []float64, radius int) []float64 {
+	if radius == 0 || len(score) < 3 {
+		return score
+	}
+
+	seq := make([]float64, len(score))
+	for i := 1; i < len(score); i++ {
+		sum, n := 0.0, 0
+		for j := max(1, i-radius); j <= min(len(score)-1, i+radius); j++ {
+			sum += score[j]
+			n++
+		}
+		seq[i] = sum / float64(n)
+	}
+	return seq
+}
+
+// depth score of each gap is the distance to peaks on left and right sides
+func depths(score []float64) []float64 {
+	depth := make([]float64, len(score))
+	for i := 1; i < len(score); i++ {
+		lpeak := score[i]
+		for j := i - 1; j >= 1 && score[j] >= lpeak; j-- {
+			lpeak = score[j]
+		}
+
+		rpeak := score[i]
+		for j := i + 1; j < len(score) && score[j] >= rpeak; j++ {
+			rpeak = score[j]
+		}
+
+		depth[i] = (lpeak - score[i]) + (rpeak - score[i])
+	}
+	return depth
+}
@@ -0,0 +1,131 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package scanner_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio/scanner"
+)
+
+func TestTextTiling(t *testing.T) {
+	text := "Cats purr. Cats meow. Cats sleep. Dogs bark. Dogs run. Dogs fetch."
+
+	t.Run("Topics", func(t *testing.T) {
+		s := scanner.NewTextTiling(scanner.NewSentences(strings.NewReader(text)))
+		s.BlockSize(2)
+
+		it.Then(t).Should(
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("Cats purr.", "Cats meow.", "Cats sleep."),
+			it.Equal(s.Chunk().ID, "0:0"),
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("Dogs bark.", "Dogs run.", "Dogs fetch."),
+			it.Equal(s.Chunk().ID, "0:3"),
+		)
+
+		it.Then(t).ShouldNot(
+			it.True(s.Scan()),
+		)
+		it.Then(t).Should(
+			it.Nil(s.Err()),
+		)
+	})
+
+	t.Run("Offsets", func(t *testing.T) {
+		s := scanner.NewTextTiling(scanner.NewSentenceSpans(strings.NewReader(text)))
+		s.BlockSize(2)
+
+		for s.Scan() {
+			for _, x := range s.Chunk().Sentences {
+				it.Then(t).Should(it.Equal(text[x.Offset:x.Offset+x.Length], x.Text))
+			}
+		}
+	})
+
+	t.Run("Cohesive", func(t *testing.T) {
+		s := scanner.NewTextTiling(scanner.NewSentences(strings.NewReader("Cats purr. Cats meow. Cats sleep.")))
+
+		it.Then(t).Should(
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("Cats purr.", "Cats meow.", "Cats sleep."),
+		)
+
+		it.Then(t).ShouldNot(
+			it.True(s.Scan()),
+		)
+	})
+
+	t.Run("Sections", func(t *testing.T) {
+		doc := "# Cats\n\nCats purr. Cats meow.\n\n# Dogs\n\nCats bark. Cats run.\n"
+		s := scanner.NewTextTiling(scanner.NewMarkdown(strings.NewReader(doc)))
+
+		it.Then(t).Should(
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("Cats purr.", "Cats meow."),
+			it.Seq(s.Chunk().Section.Path).Equal("Cats"),
+			it.True(s.Scan()),
+			it.Seq(s.Text()).Equal("Cats bark.", "Cats run."),
+			it.Seq(s.Chunk().Section.Path).Equal("Dogs"),
+		)
+
+		it.Then(t).ShouldNot(
+			it.True(s.Scan()),
+		)
+	})
+
+	t.Run("Window", func(t *testing.T) {
+		topics := strings.TrimSpace(strings.Repeat("Cats purr. Cats meow. Cats sleep. Dogs bark. Dogs run. Dogs fetch. ", 10))
+		r := &reads{Reader: scanner.NewSentences(strings.NewReader(topics))}
+		s := scanner.NewTextTiling(r)
+		s.BlockSize(2)
+		s.Window(8)
+
+		it.Then(t).Should(
+			it.True(s.Scan()),
+			it.True(r.n <= 9),
+		)
+
+		index := len(s.Chunk().Sentences)
+		for s.Scan() {
+			c := s.Chunk()
+			it.Then(t).Should(
+				it.True(len(c.Sentences) <= 8),
+				it.Equal(c.Sentences[0].Index, index),
+			)
+			index += len(c.Sentences)
+		}
+
+		it.Then(t).Should(
+			it.Nil(s.Err()),
+			it.Equal(index, 60),
+		)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		s := scanner.NewTextTiling(scanner.NewSentences(strings.NewReader("")))
+
+		it.Then(t).ShouldNot(
+			it.True(s.Scan()),
+		)
+	})
+}
+
+// Reader counting sentences read
+type reads struct {
+	scanner.Reader
+	n int
+}
+
+func (r *reads) Scan() bool {
+	r.n++
+	return r.Reader.Scan()
+}
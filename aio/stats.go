@@ -0,0 +1,80 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// CacheStats is statistics of Cache usage.
+type CacheStats struct {
+	// Number of texts served from the cache.
+	Hits int
+
+	// Number of texts embedded by the embedder.
+	Misses int
+
+	// Number of misses served by concurrent request of the same text.
+	Coalesced int
+
+	// Number of failures to store vector in the cache.
+	PutFailures int
+
+	// Number of cached values that cannot be decoded.
+	DecodeFailures int
+
+	// Tokens that would have been spent without the cache.
+	TokensSaved int
+}
+
+// HitRatio is the fraction of texts served without request to embedder.
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Coalesced + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.Coalesced) / float64(total)
+}
+
+// LogValue implements [slog.LogValuer], the stats are logged as a group.
+//
+//	slog.Info("embeddings cache", "stats", c.Stats())
+func (s CacheStats) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("hits", s.Hits),
+		slog.Int("misses", s.Misses),
+		slog.Int("coalesced", s.Coalesced),
+		slog.Int("putFailures", s.PutFailures),
+		slog.Int("decodeFailures", s.DecodeFailures),
+		slog.Int("tokensSaved", s.TokensSaved),
+		slog.Float64("hitRatio", s.HitRatio()),
+	)
+}
+
+// counters of cache usage, safe for concurrent use
+type counters struct {
+	hits           atomic.Int64
+	misses         atomic.Int64
+	coalesced      atomic.Int64
+	putFailures    atomic.Int64
+	decodeFailures atomic.Int64
+	tokensSaved    atomic.Int64
+}
+
+func (c *counters) stats() CacheStats {
+	return CacheStats{
+		Hits:           int(c.hits.Load()),
+		Misses:         int(c.misses.Load()),
+		Coalesced:      int(c.coalesced.Load()),
+		PutFailures:    int(c.putFailures.Load()),
+		DecodeFailures: int(c.decodeFailures.Load()),
+		TokensSaved:    int(c.tokensSaved.Load()),
+	}
+}
@@ -0,0 +1,108 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio"
+)
+
+func TestCacheStats(t *testing.T) {
+	text := "hello world"
+
+	t.Run("HitMiss", func(t *testing.T) {
+		c := aio.NewCache(keyval{}, mockVector())
+		a, _ := c.Embedding(context.Background(), text)
+		b, _ := c.Embedding(context.Background(), text)
+		c.Embedding(context.Background(), text)
+
+		it.Then(t).Should(
+			it.Equal(a.UsedTokens, 10),
+			it.Equal(b.UsedTokens, 0),
+			it.Equal(c.Stats(), aio.CacheStats{Hits: 2, Misses: 1, TokensSaved: 20}),
+			it.Equal(c.Stats().HitRatio(), 2.0/3.0),
+		)
+	})
+
+	t.Run("DecodeFailures", func(t *testing.T) {
+		kv := keyval{}
+		c := aio.NewCache(kv, mockVector())
+		kv[string(c.HashKey(text))] = []byte{1, 2, 3}
+		c.Embedding(context.Background(), text)
+
+		it.Then(t).Should(
+			it.Equal(c.Stats(), aio.CacheStats{Misses: 1, DecodeFailures: 1}),
+		)
+	})
+
+	t.Run("PutFailures", func(t *testing.T) {
+		c := aio.NewCache(readonly{}, mockVector())
+		_, err := c.Embedding(context.Background(), text)
+
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(c.Stats(), aio.CacheStats{Misses: 1, PutFailures: 1}),
+		)
+	})
+
+	t.Run("Coalesced", func(t *testing.T) {
+		api := &blocking{mock: mockVector(), release: make(chan struct{})}
+		c := aio.NewCache(aio.NewMemory(10), api)
+
+		var waiting sync.WaitGroup
+		waiting.Add(1)
+		c.OnWait(waiting.Done)
+
+		done := make(chan int)
+		for i := 0; i < 2; i++ {
+			go func() {
+				v, _ := c.Embedding(context.Background(), text)
+				done <- v.UsedTokens
+			}()
+		}
+		waiting.Wait()
+		close(api.release)
+
+		tokens := <-done + <-done
+		it.Then(t).Should(
+			it.Equal(tokens, 10),
+			it.Equal(c.Stats(), aio.CacheStats{Misses: 1, Coalesced: 1, TokensSaved: 10}),
+		)
+	})
+
+	t.Run("LogValue", func(t *testing.T) {
+		c := aio.NewCache(keyval{}, mockVector())
+		c.Embedding(context.Background(), text)
+		c.Embedding(context.Background(), text)
+
+		var buf bytes.Buffer
+		slog.New(slog.NewTextHandler(&buf, nil)).Info("cache", "stats", c.Stats())
+
+		it.Then(t).Should(
+			it.True(strings.Contains(buf.String(), "stats.hits=1 stats.misses=1")),
+			it.True(strings.Contains(buf.String(), "stats.tokensSaved=10 stats.hitRatio=0.5")),
+		)
+	})
+}
+
+// mock key-value failing writes
+type readonly struct{}
+
+func (readonly) Get(key []byte) ([]byte, error) { return nil, nil }
+func (readonly) Put(key []byte, val []byte) error {
+	return fmt.Errorf("read only")
+}
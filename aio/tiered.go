@@ -0,0 +1,220 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// Tiered is KeyVal composed of tiers, from the fastest one (e.g. L1 memory,
+// L2 local disk, L3 remote store). It is safe for concurrent use if tiers are.
+//
+// The value is read from the first tier that has it, and promoted to faster
+// tiers (read-through). Failed tiers are skipped by reads, the lookup fails
+// only if all tiers have failed. The value is written to all tiers
+// (write-through).
+// Use WriteBehind method to write the first tier synchronously while other
+// tiers are written asynchronously from the bounded queue. Call Flush (e.g.
+// on shutdown) to wait for pending writes.
+//
+//	db := aio.NewTiered(aio.NewMemory(10000), disk, remote)
+//	db.WriteBehind(1024)
+//	defer db.Close()
+//	text := aio.NewCache(db, cli)
+type Tiered struct {
+	tiers   []KeyVal
+	queue   chan write
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending int
+	errs    []error
+	closed  bool
+}
+
+var (
+	_ KeyVal  = (*Tiered)(nil)
+	_ Deleter = (*Tiered)(nil)
+)
+
+// pending write
+type write struct{ key, val []byte }
+
+// Creates tiered KeyVal, tiers are ordered from the fastest one.
+func NewTiered(tiers ...KeyVal) *Tiered {
+	t := &Tiered{tiers: tiers}
+	t.cond = sync.NewCond(&t.mu)
+	return t
+}
+
+// WriteBehind enables asynchronous writes to tiers except the first one,
+// the queue is bounded by n writes, Put blocks if the queue is full.
+// It must be called before the first use. The default is write-through.
+func (t *Tiered) WriteBehind(n int) {
+	if t.queue != nil || len(t.tiers) < 2 {
+		return
+	}
+
+	t.queue = make(chan write, max(n, 1))
+	go t.writer()
+}
+
+// Get returns value from the first tier that has it, the value is promoted
+// to faster tiers.
+func (t *Tiered) Get(key []byte) ([]byte, error) {
+	var errs []error
+	missed := make([]KeyVal, 0, len(t.tiers))
+	for i, kv := range t.tiers {
+		val, err := kv.Get(key)
+		if err != nil {
+			slog.Warn("failed to read cache value", "tier", i, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+
+		if len(val) == 0 {
+			missed = append(missed, kv)
+			continue
+		}
+
+		// the value is promoted to faster tiers that have missed it
+		for _, upper := range missed {
+			if err := upper.Put(key, val); err != nil {
+				slog.Warn("failed to promote cache value", "error", err)
+			}
+		}
+
+		return val, nil
+	}
+
+	if len(errs) > 0 && len(errs) == len(t.tiers) {
+		return nil, errors.Join(errs...)
+	}
+
+	return nil, nil
+}
+
+// Put writes value to all tiers, tiers except the first one are written
+// asynchronously if write-behind is enabled. It fails after Close.
+func (t *Tiered) Put(key []byte, val []byte) error {
+	t.mu.Lock()
+	closed := t.closed
+	t.mu.Unlock()
+
+	if closed {
+		return os.ErrClosed
+	}
+
+	if t.queue == nil {
+		var errs []error
+		for _, kv := range t.tiers {
+			if err := kv.Put(key, val); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	if err := t.tiers[0].Put(key, val); err != nil {
+		return err
+	}
+
+	// the pending write is awaited by Close before the queue is closed
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return os.ErrClosed
+	}
+	t.pending++
+	t.mu.Unlock()
+
+	t.queue <- write{
+		key: append([]byte(nil), key...),
+		val: append([]byte(nil), val...),
+	}
+
+	return nil
+}
+
+// Delete removes the key from all tiers implementing Deleter interface.
+// Pending writes are flushed before.
+func (t *Tiered) Delete(key []byte) error {
+	flush := t.Flush()
+
+	var errs []error
+	for _, kv := range t.tiers {
+		if d, ok := kv.(Deleter); ok {
+			if err := d.Delete(key); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(append(errs, flush)...)
+}
+
+// Flush waits for pending asynchronous writes, it returns errors of writes
+// happened since the previous flush.
+func (t *Tiered) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for t.pending > 0 {
+		t.cond.Wait()
+	}
+
+	err := errors.Join(t.errs...)
+	t.errs = nil
+	return err
+}
+
+// Close flushes pending writes and stops the asynchronous writer.
+// Writes fail after close.
+func (t *Tiered) Close() error {
+	t.mu.Lock()
+	closed := t.closed
+	t.closed = true
+	t.mu.Unlock()
+
+	if closed {
+		return nil
+	}
+
+	err := t.Flush()
+	if t.queue != nil {
+		close(t.queue)
+	}
+	return err
+}
+
+// writes queued values to tiers except the first one
+func (t *Tiered) writer() {
+	for w := range t.queue {
+		var errs []error
+		for _, kv := range t.tiers[1:] {
+			if err := kv.Put(w.key, w.val); err != nil {
+				slog.Warn("failed to write behind cache value", "error", err)
+				errs = append(errs, err)
+			}
+		}
+
+		t.mu.Lock()
+		// errors are bounded if flush is never called
+		if len(t.errs) < 64 {
+			t.errs = append(t.errs, errs...)
+		}
+		t.pending--
+		if t.pending == 0 {
+			t.cond.Broadcast()
+		}
+		t.mu.Unlock()
+	}
+}
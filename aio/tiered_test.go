@@ -0,0 +1,202 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio"
+)
+
+func TestTiered(t *testing.T) {
+	t.Run("ReadThrough", func(t *testing.T) {
+		l1, l2, l3 := keyval{}, keyval{}, keyval{}
+		db := aio.NewTiered(l1, l2, l3)
+		l3.Put([]byte("a"), []byte("1"))
+
+		a, err := db.Get([]byte("a"))
+		b, _ := db.Get([]byte("b"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(string(a), "1"),
+			it.Equal(len(b), 0),
+			it.Equal(string(l1["a"]), "1"),
+			it.Equal(string(l2["a"]), "1"),
+		)
+	})
+
+	t.Run("ReadError", func(t *testing.T) {
+		l2 := keyval{}
+		l2.Put([]byte("a"), []byte("1"))
+
+		a, err := aio.NewTiered(broken{}, l2).Get([]byte("a"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(string(a), "1"),
+		)
+
+		_, err = aio.NewTiered(broken{}, broken{}).Get([]byte("a"))
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("WriteThrough", func(t *testing.T) {
+		l1, l2 := keyval{}, keyval{}
+		db := aio.NewTiered(l1, l2)
+
+		err := db.Put([]byte("a"), []byte("1"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(string(l1["a"]), "1"),
+			it.Equal(string(l2["a"]), "1"),
+		)
+
+		err = aio.NewTiered(l1, readonly{}).Put([]byte("a"), []byte("1"))
+		it.Then(t).ShouldNot(
+			it.Nil(err),
+		)
+	})
+
+	t.Run("WriteBehind", func(t *testing.T) {
+		l1, l2 := keyval{}, &gated{kv: keyval{}, gate: make(chan struct{})}
+		db := aio.NewTiered(l1, l2)
+		db.WriteBehind(4)
+
+		err := db.Put([]byte("a"), []byte("1"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(string(l1["a"]), "1"),
+			it.Equal(len(l2.get("a")), 0),
+		)
+
+		close(l2.gate)
+		err = db.Flush()
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(string(l2.get("a")), "1"),
+		)
+
+		it.Then(t).Should(
+			it.Nil(db.Close()),
+		)
+	})
+
+	t.Run("WriteBehindError", func(t *testing.T) {
+		db := aio.NewTiered(keyval{}, readonly{})
+		db.WriteBehind(4)
+		defer db.Close()
+
+		err := db.Put([]byte("a"), []byte("1"))
+		it.Then(t).Should(
+			it.Nil(err),
+		)
+
+		it.Then(t).ShouldNot(
+			it.Nil(db.Flush()),
+		)
+		it.Then(t).Should(
+			it.Nil(db.Flush()),
+		)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		db := aio.NewTiered(aio.NewMemory(0), aio.NewMemory(0))
+		db.WriteBehind(4)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < 100; k++ {
+					if err := db.Put([]byte{byte(i), byte(k)}, []byte("1")); err != nil {
+						return
+					}
+				}
+			}()
+		}
+
+		it.Then(t).Should(
+			it.Nil(db.Close()),
+		)
+		wg.Wait()
+
+		it.Then(t).Should(
+			it.Equal(db.Put([]byte("a"), []byte("1")), os.ErrClosed),
+			it.Nil(db.Close()),
+		)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		l1, l2 := keyval{}, keyval{}
+		db := aio.NewTiered(l1, l2)
+		db.WriteBehind(4)
+		defer db.Close()
+
+		db.Put([]byte("a"), []byte("1"))
+		err := db.Delete([]byte("a"))
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(len(l1), 0),
+			it.Equal(len(l2), 0),
+		)
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		disk, _ := aio.OpenDisk(t.TempDir())
+		defer disk.Close()
+
+		db := aio.NewTiered(aio.NewMemory(10), disk)
+		db.WriteBehind(16)
+
+		api := &counter{mock: mockVector()}
+		aio.NewCache(db, api).Embedding(context.Background(), "hello world")
+		it.Then(t).Should(it.Nil(db.Close()))
+
+		db = aio.NewTiered(aio.NewMemory(10), disk)
+		v, err := aio.NewCache(db, api).Embedding(context.Background(), "hello world")
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(api.calls, 1),
+			it.Equal(len(v.Vector), 10),
+		)
+	})
+}
+
+// mock key-value blocking writes until the gate is open
+type gated struct {
+	sync.Mutex
+	kv   keyval
+	gate chan struct{}
+}
+
+func (g *gated) get(key string) []byte {
+	g.Lock()
+	defer g.Unlock()
+	return g.kv[key]
+}
+
+func (g *gated) Get(key []byte) ([]byte, error) { return g.get(string(key)), nil }
+func (g *gated) Put(key []byte, val []byte) error {
+	<-g.gate
+	g.Lock()
+	defer g.Unlock()
+	return g.kv.Put(key, val)
+}
+
+// mock key-value failing reads
+type broken struct{ keyval }
+
+func (broken) Get(key []byte) ([]byte, error) { return nil, fmt.Errorf("broken") }
@@ -0,0 +1,123 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package aio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+// The value of cache is self-describing binary format (little endian):
+//
+//	version    uint8
+//	dtype      uint8
+//	dimension  uint32
+//	usedTokens uint32
+//	created    int64 (unix milliseconds)
+//	vector     [...]byte (encoded by codec defined by dtype)
+//	checksum   uint32 (CRC-32C of preceding bytes)
+const (
+	valueVersion    = 1
+	valueHeaderSize = 1 + 1 + 4 + 4 + 8
+	valueCheckSize  = 4
+)
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// errCorrupted is returned for values that cannot be decoded
+var errCorrupted = errors.New("corrupted value")
+
+// errLegacy is returned for values of legacy format (raw float32 vector
+// without header), they are misses overwritten by the cache.
+var errLegacy = errors.New("legacy value")
+
+// value of cache
+type value struct {
+	vector     []float32
+	usedTokens int
+	created    time.Time
+}
+
+func encodeValue(v value, codec Codec) ([]byte, error) {
+	size := codec.size(len(v.vector))
+	b := make([]byte, valueHeaderSize+size+valueCheckSize)
+
+	b[0] = valueVersion
+	b[1] = byte(codec)
+	binary.LittleEndian.PutUint32(b[2:6], uint32(len(v.vector)))
+	binary.LittleEndian.PutUint32(b[6:10], uint32(v.usedTokens))
+	binary.LittleEndian.PutUint64(b[10:18], uint64(v.created.UnixMilli()))
+
+	p := valueHeaderSize + size
+	if err := codec.encode(b[valueHeaderSize:p], v.vector); err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint32(b[p:], crc32.Checksum(b[:p], crc32c))
+
+	return b, nil
+}
+
+func decodeValue(b []byte) (value, error) {
+	if isLegacyValue(b) {
+		return value{}, errLegacy
+	}
+
+	if len(b) < valueHeaderSize+valueCheckSize {
+		return value{}, fmt.Errorf("%w: too short", errCorrupted)
+	}
+
+	p := len(b) - valueCheckSize
+	if crc32.Checksum(b[:p], crc32c) != binary.LittleEndian.Uint32(b[p:]) {
+		return value{}, fmt.Errorf("%w: checksum mismatch", errCorrupted)
+	}
+
+	if b[0] != valueVersion {
+		return value{}, fmt.Errorf("%w: unsupported version %d", errCorrupted, b[0])
+	}
+
+	codec := Codec(b[1])
+	dim := int(binary.LittleEndian.Uint32(b[2:6]))
+	size := codec.size(dim)
+	if size < 0 {
+		return value{}, fmt.Errorf("%w: unsupported codec %s", errCorrupted, codec)
+	}
+
+	if p-valueHeaderSize != size {
+		return value{}, fmt.Errorf("%w: dimension mismatch", errCorrupted)
+	}
+
+	v := value{
+		vector:     make([]float32, dim),
+		usedTokens: int(binary.LittleEndian.Uint32(b[6:10])),
+		created:    time.UnixMilli(int64(binary.LittleEndian.Uint64(b[10:18]))),
+	}
+
+	codec.decode(b[valueHeaderSize:p], v.vector)
+
+	return v, nil
+}
+
+// checks if the value is legacy raw float32 vector. The header of value is
+// not consistent with its length, the corrupted value of current format
+// keeps the header.
+func isLegacyValue(b []byte) bool {
+	if len(b)%4 != 0 {
+		return false
+	}
+
+	if len(b) < valueHeaderSize+valueCheckSize || b[0] != valueVersion {
+		return true
+	}
+
+	dim := int(binary.LittleEndian.Uint32(b[2:6]))
+	return Codec(b[1]).size(dim) != len(b)-valueHeaderSize-valueCheckSize
+}
@@ -10,7 +10,7 @@ replace github.com/kshard/embeddings/llm/openai => ../llm/openai
 // replace github.com/fogfish/word2vec => ./word2vec
 
 require (
-	github.com/kshard/embeddings v0.2.0
+	github.com/kshard/embeddings v0.3.0
 	github.com/kshard/embeddings/llm/bedrock v0.0.0
 	github.com/kshard/embeddings/llm/openai v0.0.0
 // github.com/kshard/embeddings/llm/word2vec v0.0.0
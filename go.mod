@@ -9,6 +9,7 @@ require (
 	github.com/fogfish/golem/optics v0.14.0
 	github.com/fogfish/golem/trait v0.3.0
 	github.com/fogfish/it/v2 v2.2.1
+	golang.org/x/net v0.38.0
 	golang.org/x/time v0.11.0
 )
 
@@ -45,6 +45,22 @@ func New(opt ...Option) (*Client, error) {
 // Number of tokens consumed within the session
 func (c *Client) UsedTokens() int { return c.usedTokens }
 
+// Model returns identity of the embedding model, it namespaces cache keys.
+// Titan V2 normalizes vectors by default.
+func (c *Client) Model() embeddings.Model {
+	dim := c.embeddingSize
+	if dim == 0 {
+		dim = dimensions[c.model]
+	}
+
+	return embeddings.Model{
+		Provider:   "bedrock",
+		ID:         string(c.model),
+		Dimensions: dim,
+		Normalized: c.model == TITAN_EMBED_TEXT_V2,
+	}
+}
+
 // Calculates embedding vector
 func (c *Client) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
 	body, err := json.Marshal(
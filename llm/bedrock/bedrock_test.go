@@ -0,0 +1,45 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package bedrock_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio"
+	"github.com/kshard/embeddings/llm/bedrock"
+)
+
+func TestModel(t *testing.T) {
+	v256, _ := bedrock.New(bedrock.WithTitanV2, bedrock.WithEmbeddingSize256, bedrock.WithBedrock(mock{}))
+	v1024, _ := bedrock.New(bedrock.WithTitanV2, bedrock.WithEmbeddingSize1024, bedrock.WithBedrock(mock{}))
+	v2, _ := bedrock.New(bedrock.WithTitanV2, bedrock.WithBedrock(mock{}))
+
+	a := aio.NewCache(aio.NewMemory(10), v256)
+	b := aio.NewCache(aio.NewMemory(10), v1024)
+	c := aio.NewCache(aio.NewMemory(10), v2)
+
+	it.Then(t).Should(
+		it.Equal(v256.Model().Provider, "bedrock"),
+		it.Equal(v256.Model().ID, "amazon.titan-embed-text-v2:0"),
+		it.Equal(v256.Model().Dimensions, 256),
+		it.Equal(v2.Model().Dimensions, 1024),
+		it.Equal(string(b.HashKey("text")), string(c.HashKey("text"))),
+	).ShouldNot(
+		it.Equal(string(a.HashKey("text")), string(b.HashKey("text"))),
+	)
+}
+
+type mock struct{}
+
+func (mock) InvokeModel(ctx context.Context, params *bedrockruntime.InvokeModelInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.InvokeModelOutput, error) {
+	return nil, nil
+}
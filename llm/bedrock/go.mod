@@ -11,8 +11,9 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/bedrockruntime v1.27.0
 	github.com/aws/constructs-go/constructs/v10 v10.4.2
 	github.com/aws/jsii-runtime-go v1.110.0
+	github.com/fogfish/it/v2 v2.2.1
 	github.com/fogfish/opts v0.0.5
-	github.com/kshard/embeddings v0.2.0
+	github.com/kshard/embeddings v0.3.0
 )
 
 require (
@@ -42,5 +43,6 @@ require (
 	golang.org/x/mod v0.24.0 // indirect
 	golang.org/x/sync v0.12.0 // indirect
 	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/time v0.11.0 // indirect
 	golang.org/x/tools v0.31.0 // indirect
 )
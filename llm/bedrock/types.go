@@ -32,6 +32,12 @@ const (
 	EMBEDDING_SIZE_1024 = 1024
 )
 
+// default dimensions of embedding vectors produced by models
+var dimensions = map[LLM]int{
+	TITAN_EMBED_TEXT_V1: 1536,
+	TITAN_EMBED_TEXT_V2: 1024,
+}
+
 type Option = opts.Option[Client]
 
 func (c *Client) checkRequired() error {
@@ -101,7 +107,10 @@ type Client struct {
 	usedTokens    int
 }
 
-var _ embeddings.Embedder = (*Client)(nil)
+var (
+	_ embeddings.Embedder = (*Client)(nil)
+	_ embeddings.Modeler  = (*Client)(nil)
+)
 
 type request struct {
 	Text       string `json:"inputText"`
@@ -6,9 +6,10 @@ toolchain go1.24.1
 
 require (
 	github.com/fogfish/gurl/v2 v2.10.0
+	github.com/fogfish/it/v2 v2.2.1
 	github.com/fogfish/opts v0.0.5
 	github.com/jdxcode/netrc v1.0.0
-	github.com/kshard/embeddings v0.2.0
+	github.com/kshard/embeddings v0.3.0
 )
 
 require (
@@ -17,4 +18,5 @@ require (
 	github.com/fogfish/golem/optics v0.14.0 // indirect
 	github.com/google/go-cmp v0.7.0 // indirect
 	golang.org/x/net v0.38.0 // indirect
+	golang.org/x/time v0.11.0 // indirect
 )
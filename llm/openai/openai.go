@@ -49,6 +49,16 @@ func New(opt ...Option) (*Client, error) {
 // Number of tokens consumed within the session
 func (c *Client) UsedTokens() int { return c.usedTokens }
 
+// Model returns identity of the embedding model, it namespaces cache keys.
+func (c *Client) Model() embeddings.Model {
+	return embeddings.Model{
+		Provider:   "openai",
+		ID:         string(c.model),
+		Dimensions: dimensions[c.model],
+		Normalized: true,
+	}
+}
+
 // Calculates embedding vector
 func (c *Client) Embedding(ctx context.Context, text string) (embeddings.Embedding, error) {
 	bag, err := http.IO[embedding](c.WithContext(ctx),
@@ -0,0 +1,37 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package openai_test
+
+import (
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/aio"
+	"github.com/kshard/embeddings/llm/openai"
+)
+
+func TestModel(t *testing.T) {
+	small, err := openai.New(openai.WithLLM(openai.TEXT_EMBEDDING_3_SMALL), openai.WithSecret("secret"))
+	it.Then(t).Should(it.Nil(err))
+
+	large, err := openai.New(openai.WithLLM(openai.TEXT_EMBEDDING_3_LARGE), openai.WithSecret("secret"))
+	it.Then(t).Should(it.Nil(err))
+
+	a := aio.NewCache(aio.NewMemory(10), small)
+	b := aio.NewCache(aio.NewMemory(10), large)
+
+	it.Then(t).Should(
+		it.Equal(small.Model().Provider, "openai"),
+		it.Equal(small.Model().ID, "text-embedding-3-small"),
+		it.Equal(small.Model().Dimensions, 1536),
+		it.Equal(large.Model().Dimensions, 3072),
+	).ShouldNot(
+		it.Equal(string(a.HashKey("text")), string(b.HashKey("text"))),
+	)
+}
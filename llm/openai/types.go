@@ -28,6 +28,13 @@ const (
 	TEXT_ADA_002           = LLM("text-embedding-ada-002")
 )
 
+// dimensions of embedding vectors produced by models
+var dimensions = map[LLM]int{
+	TEXT_EMBEDDING_3_SMALL: 1536,
+	TEXT_EMBEDDING_3_LARGE: 3072,
+	TEXT_ADA_002:           1536,
+}
+
 type Option = opts.Option[Client]
 
 func (c *Client) checkRequired() error {
@@ -88,7 +95,10 @@ type Client struct {
 	usedTokens int
 }
 
-var _ embeddings.Embedder = (*Client)(nil)
+var (
+	_ embeddings.Embedder = (*Client)(nil)
+	_ embeddings.Modeler  = (*Client)(nil)
+)
 
 type request struct {
 	Model LLM    `json:"model"`
@@ -8,13 +8,57 @@
 
 package embeddings
 
-import "context"
+import (
+	"context"
+	"strconv"
+)
 
 type Embedder interface {
 	UsedTokens() int
 	Embedding(ctx context.Context, text string) (Embedding, error)
 }
 
+// BatchEmbedder is an optional interface implemented by Embedder.
+// It calculates embeddings for multiple texts within single request,
+// embeddings are returned in the order of texts.
+type BatchEmbedder interface {
+	Embedder
+	Embeddings(ctx context.Context, text []string) ([]Embedding, error)
+}
+
+// Model is the identity of embedding model. Vectors of different models are
+// not compatible even if dimensions are equal.
+type Model struct {
+	// Provider of the model (e.g. bedrock, openai).
+	Provider string
+
+	// Model identifier as defined by provider (e.g. amazon.titan-embed-text-v2:0).
+	ID string
+
+	// Dimensions of embedding vectors, 0 if unknown.
+	Dimensions int
+
+	// Vectors are normalized by the model.
+	Normalized bool
+
+	// Type of input the vectors are optimized for (e.g. search_query).
+	InputType string
+}
+
+// String returns the canonical representation of the model identity.
+func (m Model) String() string {
+	return m.Provider + "/" + m.ID +
+		"?dimensions=" + strconv.Itoa(m.Dimensions) +
+		"&normalized=" + strconv.FormatBool(m.Normalized) +
+		"&input=" + m.InputType
+}
+
+// Modeler is an optional interface implemented by Embedder.
+// It reports the identity of the embedding model.
+type Modeler interface {
+	Model() Model
+}
+
 // Embeddings
 type Embedding struct {
 	Text       string
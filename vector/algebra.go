@@ -0,0 +1,212 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package vector
+
+import (
+	"container/heap"
+	"slices"
+
+	"github.com/chewxy/math32"
+)
+
+// Norm is the Euclidean (L2) norm of the vector.
+func Norm(v []float32) float32 {
+	vv := float32(0.0)
+	n := len(v) - len(v)%4
+
+	for i := 0; i < n; i += 4 {
+		vsl := v[i : i+4 : i+4]
+		vv += vsl[0]*vsl[0] + vsl[1]*vsl[1] + vsl[2]*vsl[2] + vsl[3]*vsl[3]
+	}
+
+	for i := n; i < len(v); i++ {
+		vv += v[i] * v[i]
+	}
+
+	return math32.Sqrt(vv)
+}
+
+// Normalize returns the unit vector of the same direction.
+// The zero vector is returned as is (copy).
+func Normalize(v []float32) []float32 {
+	norm := Norm(v)
+	if norm == 0 {
+		return slices.Clone(v)
+	}
+
+	return Scale(v, 1/norm)
+}
+
+// Scale returns the vector multiplied by the scalar.
+func Scale(v []float32, k float32) []float32 {
+	r := make([]float32, len(v))
+	n := len(v) - len(v)%4
+
+	for i := 0; i < n; i += 4 {
+		vsl := v[i : i+4 : i+4]
+		rsl := r[i : i+4 : i+4]
+		rsl[0], rsl[1], rsl[2], rsl[3] = vsl[0]*k, vsl[1]*k, vsl[2]*k, vsl[3]*k
+	}
+
+	for i := n; i < len(v); i++ {
+		r[i] = v[i] * k
+	}
+
+	return r
+}
+
+// Add accumulates the vector v into the vector acc (acc += v).
+func Add(acc, v []float32) error {
+	return addScaled(acc, v, 1)
+}
+
+// acc += v * k
+func addScaled(acc, v []float32, k float32) error {
+	if len(acc) != len(v) {
+		return DimensionError{len(acc), len(v)}
+	}
+
+	n := len(v) - len(v)%4
+	for i := 0; i < n; i += 4 {
+		asl := acc[i : i+4 : i+4]
+		vsl := v[i : i+4 : i+4]
+		asl[0] += vsl[0] * k
+		asl[1] += vsl[1] * k
+		asl[2] += vsl[2] * k
+		asl[3] += vsl[3] * k
+	}
+
+	for i := n; i < len(v); i++ {
+		acc[i] += v[i] * k
+	}
+
+	return nil
+}
+
+// WeightedSum of vectors, the vector seq[i] is multiplied by weights[i].
+// It returns nil if the sequence is empty.
+func WeightedSum(seq [][]float32, weights []float32) ([]float32, error) {
+	if len(seq) != len(weights) {
+		return nil, WeightsError{len(seq), len(weights)}
+	}
+
+	if len(seq) == 0 {
+		return nil, nil
+	}
+
+	sum := make([]float32, len(seq[0]))
+	for i, v := range seq {
+		if err := addScaled(sum, v, weights[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return sum, nil
+}
+
+// Mean pooling of vectors, it is arithmetic mean (e.g. pooling of token
+// embeddings into the text embedding). It returns nil if the sequence is
+// empty.
+func Mean(seq ...[]float32) ([]float32, error) {
+	if len(seq) == 0 {
+		return nil, nil
+	}
+
+	sum := make([]float32, len(seq[0]))
+	for _, v := range seq {
+		if err := Add(sum, v); err != nil {
+			return nil, err
+		}
+	}
+
+	return Scale(sum, 1/float32(len(seq))), nil
+}
+
+// Centroid of vectors on the unit sphere, it is the normalized mean of
+// normalized vectors. Unlike mean pooling, the magnitude of vectors does not
+// contribute to the centroid, which is the best representative of vectors
+// with respect to cosine similarity. It returns nil if the sequence is empty.
+func Centroid(seq ...[]float32) ([]float32, error) {
+	if len(seq) == 0 {
+		return nil, nil
+	}
+
+	sum := make([]float32, len(seq[0]))
+	for _, v := range seq {
+		norm := Norm(v)
+		if norm == 0 {
+			norm = 1
+		}
+
+		if err := addScaled(sum, v, 1/norm); err != nil {
+			return nil, err
+		}
+	}
+
+	return Normalize(sum), nil
+}
+
+// Hit is the result of nearest neighbours search.
+type Hit struct {
+	// Index of the vector in the sequence.
+	Index int
+
+	// Cosine distance of the vector to the query.
+	Distance float32
+}
+
+// TopK returns k nearest neighbours of the query among vectors, nearest
+// first. The cosine distance is used.
+func TopK(k int, query []float32, seq [][]float32) ([]Hit, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+
+	// max-heap of k nearest hits, the farthest one is at the top
+	h := make(hits, 0, min(k, len(seq)))
+	for i, v := range seq {
+		d, err := Cosine(query, v)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case len(h) < k:
+			heap.Push(&h, Hit{Index: i, Distance: d})
+		case d < h[0].Distance:
+			h[0] = Hit{Index: i, Distance: d}
+			heap.Fix(&h, 0)
+		}
+	}
+
+	top := make([]Hit, len(h))
+	for i := len(h) - 1; i >= 0; i-- {
+		top[i] = heap.Pop(&h).(Hit)
+	}
+
+	return top, nil
+}
+
+type hits []Hit
+
+func (h hits) Len() int { return len(h) }
+func (h hits) Less(i, j int) bool {
+	if h[i].Distance == h[j].Distance {
+		return h[i].Index > h[j].Index
+	}
+	return h[i].Distance > h[j].Distance
+}
+func (h hits) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *hits) Push(x any)   { *h = append(*h, x.(Hit)) }
+func (h *hits) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
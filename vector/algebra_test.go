@@ -0,0 +1,182 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package vector_test
+
+import (
+	"testing"
+
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/vector"
+)
+
+func TestNorm(t *testing.T) {
+	it.Then(t).Should(
+		it.Equal(vector.Norm([]float32{3, 0, 0, 0, 4}), 5),
+		it.Equal(vector.Norm(nil), 0),
+	)
+}
+
+func TestNormalize(t *testing.T) {
+	it.Then(t).Should(
+		it.Seq(vector.Normalize([]float32{3, 0, 0, 0, 4})).Equal(0.6, 0, 0, 0, 0.8),
+		it.Seq(vector.Normalize([]float32{0, 0})).Equal(0, 0),
+	)
+}
+
+func TestScale(t *testing.T) {
+	it.Then(t).Should(
+		it.Seq(vector.Scale([]float32{1, 2, 3, 4, 5}, 2)).Equal(2, 4, 6, 8, 10),
+	)
+}
+
+func TestAdd(t *testing.T) {
+	acc := []float32{1, 2, 3, 4, 5}
+	err := vector.Add(acc, []float32{1, 1, 1, 1, 1})
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Seq(acc).Equal(2, 3, 4, 5, 6),
+	)
+
+	it.Then(t).ShouldNot(
+		it.Nil(vector.Add(acc, []float32{1})),
+	)
+}
+
+func TestWeightedSum(t *testing.T) {
+	v, err := vector.WeightedSum(
+		[][]float32{{1, 0, 1}, {0, 1, 1}},
+		[]float32{2, 3},
+	)
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Seq(v).Equal(2, 3, 5),
+	)
+
+	_, err = vector.WeightedSum([][]float32{{1, 0, 1}}, []float32{2, 3})
+	it.Then(t).Should(
+		it.Equal(err.Error(), "vector weights mismatch: 1 vectors, 2 weights"),
+	)
+}
+
+func TestMean(t *testing.T) {
+	v, err := vector.Mean([]float32{1, 2, 3}, []float32{3, 4, 5})
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Seq(v).Equal(2, 3, 4),
+	)
+
+	v, err = vector.Mean()
+	it.Then(t).Should(
+		it.Nil(err),
+		it.True(v == nil),
+	)
+
+	_, err = vector.Mean([]float32{1, 2, 3}, []float32{3, 4})
+	it.Then(t).ShouldNot(
+		it.Nil(err),
+	)
+}
+
+func TestCentroid(t *testing.T) {
+	v, err := vector.Centroid([]float32{10, 0}, []float32{0, 1})
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(v[0], v[1]),
+		it.Less(vector.Norm(v)-1, 1e-6),
+	)
+}
+
+func TestTopK(t *testing.T) {
+	seq := [][]float32{
+		{0, 1, 0},
+		{1, 0, 0},
+		{-1, 0, 0},
+		{1, 1, 0},
+		{1, 0, 0},
+	}
+
+	top, err := vector.TopK(3, []float32{1, 0, 0}, seq)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(len(top), 3),
+		it.Equal(top[0].Index, 1),
+		it.Equal(top[1].Index, 4),
+		it.Equal(top[2].Index, 3),
+		it.Equal(top[0].Distance, 0),
+	)
+
+	top, err = vector.TopK(10, []float32{1, 0, 0}, seq)
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(len(top), 5),
+		it.Equal(top[4].Index, 2),
+	)
+
+	_, err = vector.TopK(1, []float32{1, 0}, seq)
+	it.Then(t).ShouldNot(
+		it.Nil(err),
+	)
+}
+
+func BenchmarkNormalize(b *testing.B) {
+	v := sample(1024, 0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vector.Normalize(v)
+	}
+}
+
+func BenchmarkMean(b *testing.B) {
+	seq := make([][]float32, 32)
+	for i := range seq {
+		seq[i] = sample(1024, i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vector.Mean(seq...)
+	}
+}
+
+func BenchmarkCentroid(b *testing.B) {
+	seq := make([][]float32, 32)
+	for i := range seq {
+		seq[i] = sample(1024, i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vector.Centroid(seq...)
+	}
+}
+
+func BenchmarkTopK(b *testing.B) {
+	seq := make([][]float32, 1000)
+	for i := range seq {
+		seq[i] = sample(256, i)
+	}
+	q := sample(256, 42)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vector.TopK(10, q, seq)
+	}
+}
+
+func sample(n, seed int) []float32 {
+	v := make([]float32, n)
+	for i := range v {
+		v[i] = float32((i*31+seed*17)%97) - 48
+	}
+	return v
+}
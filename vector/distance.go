@@ -0,0 +1,167 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+// Package vector implements distance functions on embedding vectors.
+// Functions handle vectors of any dimension, dimension mismatch is reported
+// as an error. The zero vector has no direction, it is maximally distant
+// from any vector, including another zero vector.
+package vector
+
+import (
+	"fmt"
+
+	"github.com/chewxy/math32"
+)
+
+// DimensionError is returned when vectors have different dimensions.
+type DimensionError struct{ A, B int }
+
+func (e DimensionError) Error() string {
+	return fmt.Sprintf("vector dimensions mismatch: %d != %d", e.A, e.B)
+}
+
+// WeightsError is returned when number of weights differs from number of
+// vectors.
+type WeightsError struct{ Vectors, Weights int }
+
+func (e WeightsError) Error() string {
+	return fmt.Sprintf("vector weights mismatch: %d vectors, %d weights", e.Vectors, e.Weights)
+}
+
+// Dot product of vectors.
+func Dot(a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, DimensionError{len(a), len(b)}
+	}
+
+	ab := float32(0.0)
+	n := len(a) - len(a)%4
+
+	for i := 0; i < n; i += 4 {
+		asl := a[i : i+4 : i+4]
+		bsl := b[i : i+4 : i+4]
+		ab += asl[0]*bsl[0] + asl[1]*bsl[1] + asl[2]*bsl[2] + asl[3]*bsl[3]
+	}
+
+	for i := n; i < len(a); i++ {
+		ab += a[i] * b[i]
+	}
+
+	return ab, nil
+}
+
+// CosineSimilarity of vectors is within [-1, 1], 1 means proportional
+// vectors, 0 orthogonal, -1 opposite. It is -1 if any vector is zero.
+func CosineSimilarity(a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, DimensionError{len(a), len(b)}
+	}
+
+	return similarity(a, b), nil
+}
+
+// Cosine distance of vectors is scaled to [0, 1]:
+// two proportional vectors have distance 0, two orthogonal vectors have
+// distance 0.5 and two opposite vectors have distance 1.0.
+// The distance is 1 if any vector is zero.
+func Cosine(a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, DimensionError{len(a), len(b)}
+	}
+
+	return (1 - similarity(a, b)) / 2, nil
+}
+
+// Angular distance of vectors is the angle between them scaled to [0, 1],
+// unlike cosine distance it is the metric. The distance is 1 if any vector
+// is zero.
+func Angular(a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, DimensionError{len(a), len(b)}
+	}
+
+	return math32.Acos(similarity(a, b)) / math32.Pi, nil
+}
+
+// Euclidean distance of vectors.
+func Euclidean(a, b []float32) (float32, error) {
+	if len(a) != len(b) {
+		return 0, DimensionError{len(a), len(b)}
+	}
+
+	d := float32(0.0)
+	n := len(a) - len(a)%4
+
+	for i := 0; i < n; i += 4 {
+		asl := a[i : i+4 : i+4]
+		bsl := b[i : i+4 : i+4]
+
+		d0 := asl[0] - bsl[0]
+		d1 := asl[1] - bsl[1]
+		d2 := asl[2] - bsl[2]
+		d3 := asl[3] - bsl[3]
+		d += d0*d0 + d1*d1 + d2*d2 + d3*d3
+	}
+
+	for i := n; i < len(a); i++ {
+		di := a[i] - b[i]
+		d += di * di
+	}
+
+	return math32.Sqrt(d), nil
+}
+
+// cosine similarity of vectors with equal dimensions, clamped to [-1, 1]
+func similarity(a, b []float32) float32 {
+	ab, aa, bb := dot(a, b)
+	if aa == 0 || bb == 0 {
+		return -1
+	}
+
+	s := ab / (math32.Sqrt(aa) * math32.Sqrt(bb))
+	return min(max(s, -1), 1)
+}
+
+// dot products a⋅b, a⋅a and b⋅b of vectors with equal dimensions
+func dot(a, b []float32) (ab, aa, bb float32) {
+	n := len(a) - len(a)%4
+
+	for i := 0; i < n; i += 4 {
+		asl := a[i : i+4 : i+4]
+		bsl := b[i : i+4 : i+4]
+
+		ax0, ax1, ax2, ax3 := asl[0], asl[1], asl[2], asl[3]
+		bx0, bx1, bx2, bx3 := bsl[0], bsl[1], bsl[2], bsl[3]
+
+		ab0 := ax0 * bx0
+		ab1 := ax1 * bx1
+		ab2 := ax2 * bx2
+		ab3 := ax3 * bx3
+		ab += ab0 + ab1 + ab2 + ab3
+
+		aa0 := ax0 * ax0
+		aa1 := ax1 * ax1
+		aa2 := ax2 * ax2
+		aa3 := ax3 * ax3
+		aa += aa0 + aa1 + aa2 + aa3
+
+		bb0 := bx0 * bx0
+		bb1 := bx1 * bx1
+		bb2 := bx2 * bx2
+		bb3 := bx3 * bx3
+		bb += bb0 + bb1 + bb2 + bb3
+	}
+
+	for i := n; i < len(a); i++ {
+		ab += a[i] * b[i]
+		aa += a[i] * a[i]
+		bb += b[i] * b[i]
+	}
+
+	return
+}
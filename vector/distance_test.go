@@ -0,0 +1,113 @@
+//
+// Copyright (C) 2025 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/kshard/embeddings
+//
+
+package vector_test
+
+import (
+	"testing"
+
+	"github.com/chewxy/math32"
+	"github.com/fogfish/it/v2"
+	"github.com/kshard/embeddings/vector"
+)
+
+func TestDot(t *testing.T) {
+	d, err := vector.Dot([]float32{1, 2, 3, 4, 5}, []float32{1, 1, 1, 1, 2})
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(d, 20),
+	)
+}
+
+func TestCosine(t *testing.T) {
+	for _, tt := range []struct {
+		a, b []float32
+		d    float32
+	}{
+		{[]float32{1, 0, 0, 0}, []float32{2, 0, 0, 0}, 0.0},
+		{[]float32{1, 0, 0, 0}, []float32{0, 1, 0, 0}, 0.5},
+		{[]float32{1, 0, 0, 0}, []float32{-1, 0, 0, 0}, 1.0},
+		{[]float32{1, 1, 0}, []float32{2, 2, 0}, 0.0},
+		{[]float32{1}, []float32{-3}, 1.0},
+		{[]float32{0, 0, 0}, []float32{1, 2, 3}, 1.0},
+		{[]float32{0, 0, 0}, []float32{0, 0, 0}, 1.0},
+		{[]float32{}, []float32{}, 1.0},
+	} {
+		d, err := vector.Cosine(tt.a, tt.b)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Equal(d, tt.d),
+		)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	s, err := vector.CosineSimilarity([]float32{1, 1, 0, 0, 1}, []float32{-1, -1, 0, 0, -1})
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(s, -1),
+	)
+}
+
+func TestAngular(t *testing.T) {
+	for _, tt := range []struct {
+		a, b []float32
+		d    float32
+	}{
+		{[]float32{1, 0, 0}, []float32{1, 0, 0}, 0.0},
+		{[]float32{1, 0, 0}, []float32{0, 1, 0}, 0.5},
+		{[]float32{1, 0, 0}, []float32{-1, 0, 0}, 1.0},
+		{[]float32{0, 0, 0}, []float32{-1, 0, 0}, 1.0},
+	} {
+		d, err := vector.Angular(tt.a, tt.b)
+		it.Then(t).Should(
+			it.Nil(err),
+			it.Less(math32.Abs(d-tt.d), 1e-6),
+		)
+	}
+}
+
+func TestEuclidean(t *testing.T) {
+	d, err := vector.Euclidean([]float32{1, 2, 3, 4, 5}, []float32{1, 2, 3, 4, 2})
+
+	it.Then(t).Should(
+		it.Nil(err),
+		it.Equal(d, 3),
+	)
+}
+
+func TestDimensionMismatch(t *testing.T) {
+	a, b := []float32{1, 2, 3}, []float32{1, 2}
+
+	for _, f := range []func(a, b []float32) (float32, error){
+		vector.Dot,
+		vector.CosineSimilarity,
+		vector.Cosine,
+		vector.Angular,
+		vector.Euclidean,
+	} {
+		_, err := f(a, b)
+		it.Then(t).Should(
+			it.Equal(err.Error(), "vector dimensions mismatch: 3 != 2"),
+		)
+	}
+}
+
+func BenchmarkCosine(b *testing.B) {
+	x, y := make([]float32, 1024), make([]float32, 1024)
+	for i := range x {
+		x[i], y[i] = float32(i), float32(len(y)-i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vector.Cosine(x, y)
+	}
+}